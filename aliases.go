@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"udc2mongo/model"
+	"udc2mongo/progress"
+)
+
+// loadPropertyAliases 获取并解析指定版本的属性别名表，按其中 blk 的行填写块的别名和全名，
+// UCD_LONG_NAMES 为 true 时为字符点填写全名；块的校验错误加入 report
+//
+// 别名表使用缓存，已下载过的版本离线时也能加载；获取失败时返回错误，不会留下没有块名的数据。
+func loadPropertyAliases(ctx context.Context, version string, codePoints []model.CodePoint, blocks []model.Block, report *model.ValidationReport, run *model.ImportRun) (*model.PropertyAliases, error) {
+	longNames := false
+	if s := os.Getenv("UCD_LONG_NAMES"); s != "" {
+		var err error
+		longNames, err = strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("error reading UCD_LONG_NAMES: %w", err)
+		}
+	}
+
+	slog.Info("loading property aliases", "version", version)
+	start := time.Now()
+	aliases, err := fetchPropertyAliases(ctx, version, run)
+	if err != nil {
+		return nil, fmt.Errorf("error loading property aliases: %w", err)
+	}
+	run.Stage("aliases", start)
+
+	report.Errors = append(report.Errors, model.AssignBlocks(codePoints, blocks, aliases)...)
+
+	if longNames {
+		aliases.AssignNames(codePoints)
+	}
+	return aliases, nil
+}
+
+// fetchPropertyAliases 获取 PropertyAliases.txt 和 PropertyValueAliases.txt 并解析
+func fetchPropertyAliases(ctx context.Context, version string, run *model.ImportRun) (*model.PropertyAliases, error) {
+	var contents [][]byte
+	for _, fileName := range []string{"PropertyAliases.txt", "PropertyValueAliases.txt"} {
+		content, source, err := fetchUcdTextWithCache(ctx, version, fileName)
+		if err != nil {
+			return nil, err
+		}
+		run.Sources = append(run.Sources, source)
+		contents = append(contents, content)
+	}
+
+	return model.ParsePropertyAliases(bytes.NewReader(contents[0]), bytes.NewReader(contents[1]))
+}
+
+// fetchUcdTextWithCache 带缓存地获取 ucd 目录下的文本文件，例如 auxiliary/WordBreakTest.txt，缓存文件名包含版本
+func fetchUcdTextWithCache(ctx context.Context, version, fileName string) ([]byte, model.ImportSource, error) {
+	source := model.ImportSource{File: fileName}
+
+	fileUrl, err := url.JoinPath("https://www.unicode.org/Public/", version, "ucd", fileName)
+	if err != nil {
+		return nil, source, fmt.Errorf("failed to construct file URL: %w", err)
+	}
+	source.URL = fileUrl
+
+	cachePath := filepath.Join(os.TempDir(), "ucd-"+version+"-"+filepath.Base(fileName))
+	if isCacheValid(cachePath) {
+		slog.Debug("using cached file", "path", cachePath)
+		content, err := os.ReadFile(cachePath)
+		if err != nil {
+			return nil, source, err
+		}
+		source.Cached = true
+		source.SHA256 = sha256Hex(content)
+		return content, source, nil
+	}
+
+	slog.Info("no cache found, downloading", "url", fileUrl)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return nil, source, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, source, fmt.Errorf("failed to fetch file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, source, fmt.Errorf("failed to fetch %s: status code %d", fileName, resp.StatusCode)
+	}
+
+	tracker := reporter.Start(progress.Stage{Name: "download " + fileName, Unit: progress.Bytes, Total: resp.ContentLength})
+	content, err := io.ReadAll(progress.Reader(resp.Body, tracker))
+	tracker.Done()
+	if err != nil {
+		return nil, source, fmt.Errorf("failed to read response body: %w", err)
+	}
+	source.SHA256 = sha256Hex(content)
+
+	if err := os.WriteFile(cachePath, content, 0644); err != nil {
+		slog.Warn("failed to save cache", "path", cachePath, "error", err)
+	}
+	return content, source, nil
+}
+
+// sha256Hex 计算内容的 SHA-256
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"udc2mongo/bidi"
+	"udc2mongo/model"
+)
+
+// runBidi 用导入版本的双向属性按 UAX #9 重排文本，或者运行 BidiCharacterTest.txt
+func runBidi(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("bidi", flag.ExitOnError)
+	source := flags.String("source", "xml", "read from the UCD XML (xml) or from MONGODB_DB (mongo)")
+	dirName := flags.String("dir", "auto", "paragraph direction: ltr, rtl or auto")
+	showLevels := flags.Bool("levels", false, "print the resolved levels and visual order instead of the text")
+	test := flags.Bool("test", false, "run the official BidiCharacterTest.txt for the loaded version")
+	fixtures := flags.String("fixtures", "", "directory with BidiCharacterTest.txt, downloaded when empty")
+	flags.Parse(args)
+
+	dir, err := bidi.ParseDirection(*dirName)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	if !*test {
+		text = strings.Join(flags.Args(), " ")
+		if text == "" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("error reading standard input: %w", err)
+			}
+			text = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		}
+		if text == "" {
+			return fmt.Errorf("usage: bidi [-dir ltr|rtl|auto] [-levels] <text>, or bidi -test")
+		}
+	}
+
+	var version string
+	var codePoints []model.CodePoint
+	switch *source {
+	case "xml":
+		version = ucdVersion()
+		codePoints, _, err = loadFromXML(ctx, version)
+	case "mongo":
+		version, codePoints, _, err = loadFromMongo(ctx, mongoDBName())
+	default:
+		err = fmt.Errorf("unknown source %q", *source)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading data: %w", err)
+	}
+
+	props, err := bidi.NewProperties(codePoints)
+	if err != nil {
+		return fmt.Errorf("error building bidi properties: %w", err)
+	}
+
+	if *test {
+		return runBidiTests(ctx, props, version, *fixtures)
+	}
+
+	for _, paragraph := range props.Paragraphs(text) {
+		if !*showLevels {
+			// 段落分隔符不输出，每段一行
+			paragraph = strings.TrimRightFunc(paragraph, func(r rune) bool { return props.Class(r) == bidi.B })
+			fmt.Println(props.Paragraph(paragraph, dir).Visual())
+			continue
+		}
+
+		para := props.Paragraph(paragraph, dir)
+		levels := make([]int, para.Len())
+		for i, level := range para.Levels() {
+			levels[i] = int(level)
+		}
+		fmt.Printf("paragraph level %d\n", para.Level)
+		fmt.Printf("  levels %s\n", bidi.FormatLevels(levels))
+		fmt.Printf("  order  %s\n", bidi.FormatOrder(para.Reorder()))
+	}
+	return nil
+}
+
+// runBidiTests 运行 BidiCharacterTest.txt
+func runBidiTests(ctx context.Context, props *bidi.Properties, version, dir string) error {
+	const fileName = "BidiCharacterTest.txt"
+
+	var content []byte
+	var err error
+	if dir != "" {
+		content, err = os.ReadFile(filepath.Join(dir, fileName))
+	} else {
+		content, _, err = fetchUcdTextWithCache(ctx, version, fileName)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading %s: %w", fileName, err)
+	}
+
+	cases, err := bidi.ParseTests(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", fileName, err)
+	}
+
+	failures := props.RunTests(cases)
+	if len(failures) == 0 {
+		fmt.Printf("✓ %s passes (%d cases)\n", fileName, len(cases))
+		return nil
+	}
+
+	fmt.Printf("✗ %s: %d of %d cases fail\n", fileName, len(failures), len(cases))
+	for i, failure := range failures {
+		if i >= 5 { // 只显示前5个
+			break
+		}
+		tc := failure.Case
+		fmt.Printf("  line %d (%s)\n", tc.Line, tc.Direction)
+		fmt.Printf("    want level %d, levels %s, order %s\n", tc.Level, bidi.FormatLevels(tc.Levels), bidi.FormatOrder(tc.Order))
+		fmt.Printf("    got  level %d, levels %s, order %s\n", failure.Level, bidi.FormatLevels(failure.Levels), bidi.FormatOrder(failure.Order))
+	}
+	return fmt.Errorf("bidi resolution does not match the Unicode %s test file", version)
+}
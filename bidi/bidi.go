@@ -0,0 +1,66 @@
+// Package bidi 按 UAX #9 计算双向文本的嵌入层级和显示顺序
+//
+// 实现段落级算法（P2–P3、X1–X10、W1–W7、N0–N2、I1–I2）和行级的 L1、L2、L4。
+// 属性表从导入的 UCD 建立，结果与导入的 Unicode 版本一致。
+//
+// See: https://www.unicode.org/reports/tr9/
+package bidi
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Direction 段落方向
+type Direction string
+
+const (
+	LTR  Direction = "ltr"
+	RTL  Direction = "rtl"
+	Auto Direction = "auto" // 按 P2、P3 取第一个强类型字符的方向，没有时为从左到右
+)
+
+// ParseDirection 解析段落方向，不区分大小写
+func ParseDirection(s string) (Direction, error) {
+	switch d := Direction(strings.ToLower(s)); d {
+	case LTR, RTL, Auto:
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown direction %q, expected ltr, rtl or auto", s)
+}
+
+// Paragraphs 按 P1 在段落分隔符（Bidi_Class 为 B）之后切分文本，分隔符留在前一段，CR LF 不拆开
+func (p *Properties) Paragraphs(s string) []string {
+	var paragraphs []string
+	start := 0
+	for i, r := range s {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		end := i + size
+		if p.Class(r) != B || (r == '\r' && strings.HasPrefix(s[end:], "\n")) {
+			continue
+		}
+		paragraphs = append(paragraphs, s[start:end])
+		start = end
+	}
+	if start < len(s) {
+		paragraphs = append(paragraphs, s[start:])
+	}
+	return paragraphs
+}
+
+// Visual 按显示顺序排列段落中的字符，从右到左的字符替换为镜像字符（L4）
+//
+// 整个段落作为一行；显式格式字符保留在结果中，组合字符不按 L3 调整。
+func (para *Paragraph) Visual() string {
+	levels := para.Levels()
+	var sb strings.Builder
+	for _, i := range para.Reorder() {
+		r := para.runes[i]
+		if levels[i]%2 == 1 {
+			r, _ = para.props.Mirror(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
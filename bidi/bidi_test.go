@@ -0,0 +1,121 @@
+package bidi
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"udc2mongo/model"
+)
+
+// testdata 是 Unicode 15.0.0 的数据，但不是官方文件：BidiCharacterTest.txt 按官方格式，
+// 由 ucd.xml 中字符组成的随机文本和 golang.org/x/text v0.28.0 中 UAX #9 参考实现的结果组成；
+// ucd.xml 取自 x/text 的 15.0.0 属性表，包括括号和镜像字符的另一半。
+
+func loadProperties(t *testing.T) *Properties {
+	t.Helper()
+	data, err := os.ReadFile("testdata/ucd.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ucd, err := model.ParseUCDXML(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codePoints := model.ExtractAllCodePoints(ucd)
+	for i := range codePoints {
+		model.NormalizeCodePoint(&codePoints[i])
+	}
+	props, err := NewProperties(codePoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return props
+}
+
+func TestConformance(t *testing.T) {
+	props := loadProperties(t)
+	f, err := os.Open("testdata/BidiCharacterTest.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cases, err := ParseTests(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no test cases")
+	}
+	for _, failure := range props.RunTests(cases) {
+		tc := failure.Case
+		t.Errorf("line %d: got level %d, levels %s, order %s; want level %d, levels %s, order %s", tc.Line,
+			failure.Level, FormatLevels(failure.Levels), FormatOrder(failure.Order),
+			tc.Level, FormatLevels(tc.Levels), FormatOrder(tc.Order))
+	}
+}
+
+func TestVisual(t *testing.T) {
+	props := loadProperties(t)
+	tests := []struct {
+		dir   Direction
+		in    string
+		level uint8
+		want  string
+	}{
+		{LTR, "a \u05D0\u05EA", 0, "a \u05EA\u05D0"},
+		{Auto, "a \u05D0\u05EA", 0, "a \u05EA\u05D0"},
+		{Auto, "\u05D0(\u05EA)", 1, "(\u05EA)\u05D0"},
+		{RTL, "a(Z)", 1, "a(Z)"},
+		{RTL, "\u05D0 10", 1, "10 \u05D0"},
+		{Auto, "\u0627 \u0660\u0669", 1, "\u0660\u0669 \u0627"},
+		{Auto, "\u2067a\u2069\u05D0", 1, "\u05D0\u2069a\u2067"},
+	}
+	for _, tt := range tests {
+		para := props.Paragraph(tt.in, tt.dir)
+		if para.Level != tt.level {
+			t.Errorf("Paragraph(%+q, %s).Level = %d, want %d", tt.in, tt.dir, para.Level, tt.level)
+		}
+		if got := para.Visual(); got != tt.want {
+			t.Errorf("Paragraph(%+q, %s).Visual() = %+q, want %+q", tt.in, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestParagraphs(t *testing.T) {
+	props := loadProperties(t)
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\u2029\u05D0", []string{"a\u2029", "\u05D0"}},
+		{"a\u2029", []string{"a\u2029"}},
+	}
+	for _, tt := range tests {
+		if got := props.Paragraphs(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("Paragraphs(%+q) = %+q, want %+q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Direction
+		wantErr bool
+	}{
+		{"ltr", LTR, false},
+		{"RTL", RTL, false},
+		{"auto", Auto, false},
+		{"ttb", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDirection(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDirection(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package bidi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"udc2mongo/model"
+)
+
+// TestCase BidiCharacterTest.txt 中的一行
+//
+// See: https://www.unicode.org/Public/UCD/latest/ucd/BidiCharacterTest.txt
+type TestCase struct {
+	Line      int
+	Text      string
+	Direction Direction
+	Level     uint8 // 期望的段落层级
+	Levels    []int // 期望的层级，X9 删除的字符为 -1
+	Order     []int // 期望的显示顺序，不包括 X9 删除的字符
+}
+
+// TestFailure 结果与期望不同的测试行
+type TestFailure struct {
+	Case   TestCase
+	Level  uint8
+	Levels []int
+	Order  []int
+}
+
+// testDirections 第 2 个字段的取值
+var testDirections = map[string]Direction{"0": LTR, "1": RTL, "2": Auto}
+
+// ParseTests 解析 BidiCharacterTest.txt
+//
+// 包含代理字符点的行无法表示为 UTF-8 字符串，被跳过。
+func ParseTests(r io.Reader) ([]TestCase, error) {
+	var cases []TestCase
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("line %d: expected 5 fields, got %d", lineNo, len(fields))
+		}
+
+		tc := TestCase{Line: lineNo}
+		var sb strings.Builder
+		surrogate := false
+		for _, field := range strings.Fields(fields[0]) {
+			r, err := model.ParseCodePoint(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			surrogate = surrogate || !utf8.ValidRune(r)
+			sb.WriteRune(r)
+		}
+		if surrogate {
+			continue
+		}
+		tc.Text = sb.String()
+
+		var ok bool
+		if tc.Direction, ok = testDirections[strings.TrimSpace(fields[1])]; !ok {
+			return nil, fmt.Errorf("line %d: invalid paragraph direction %q", lineNo, fields[1])
+		}
+		level, err := strconv.ParseUint(strings.TrimSpace(fields[2]), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid paragraph level: %w", lineNo, err)
+		}
+		tc.Level = uint8(level)
+
+		for _, field := range strings.Fields(fields[3]) {
+			if field == "x" {
+				tc.Levels = append(tc.Levels, -1)
+				continue
+			}
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid level %q", lineNo, field)
+			}
+			tc.Levels = append(tc.Levels, n)
+		}
+		if len(tc.Levels) != utf8.RuneCountInString(tc.Text) {
+			return nil, fmt.Errorf("line %d: %d levels for %d code points", lineNo, len(tc.Levels), utf8.RuneCountInString(tc.Text))
+		}
+
+		for _, field := range strings.Fields(fields[4]) {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid index %q", lineNo, field)
+			}
+			tc.Order = append(tc.Order, n)
+		}
+		cases = append(cases, tc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// RunTests 用属性表运行测试，返回失败的测试行
+func (p *Properties) RunTests(cases []TestCase) []TestFailure {
+	var failures []TestFailure
+	for _, tc := range cases {
+		para := p.Paragraph(tc.Text, tc.Direction)
+		levels, order := para.testResult()
+		if para.Level != tc.Level || !slices.Equal(levels, tc.Levels) || !slices.Equal(order, tc.Order) {
+			failures = append(failures, TestFailure{Case: tc, Level: para.Level, Levels: levels, Order: order})
+		}
+	}
+	return failures
+}
+
+// testResult 以测试文件的形式返回层级和显示顺序，X9 删除的字符层级为 -1，不出现在显示顺序中
+func (para *Paragraph) testResult() ([]int, []int) {
+	levels := make([]int, para.Len())
+	for i, level := range para.Levels() {
+		levels[i] = int(level)
+		if para.Removed(i) {
+			levels[i] = -1
+		}
+	}
+	var order []int
+	for _, i := range para.Reorder() {
+		if !para.Removed(i) {
+			order = append(order, i)
+		}
+	}
+	return levels, order
+}
+
+// FormatLevels 以测试文件的格式显示层级，例如 "1 x 0"
+func FormatLevels(levels []int) string {
+	fields := make([]string, len(levels))
+	for i, level := range levels {
+		if level < 0 {
+			fields[i] = "x"
+		} else {
+			fields[i] = strconv.Itoa(level)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// FormatOrder 以测试文件的格式显示顺序，例如 "2 1 0"
+func FormatOrder(order []int) string {
+	fields := make([]string, len(order))
+	for i, index := range order {
+		fields[i] = strconv.Itoa(index)
+	}
+	return strings.Join(fields, " ")
+}
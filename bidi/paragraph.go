@@ -0,0 +1,297 @@
+package bidi
+
+// maxDepth 嵌入层级的上限（BD2）
+const maxDepth = 125
+
+// Paragraph 一个段落的解析结果，下标按字符（rune）计
+//
+// 文本中间的段落分隔符按 X8 结束所有嵌入，但段落层级只由开头决定；多段文本应先用 Paragraphs 切分。
+type Paragraph struct {
+	Level uint8 // 段落嵌入层级，0 为从左到右，1 为从右到左
+
+	props   *Properties
+	runes   []rune
+	initial []Class // 原始的 Bidi_Class
+	types   []Class // 解析中的类型
+	levels  []uint8 // 解析后的层级，X9 删除的字符取前一个字符的层级
+
+	matchingPDI       []int // 孤立起始符对应的 PDI，没有时为 -1
+	matchingInitiator []int // PDI 对应的孤立起始符，没有时为 -1
+}
+
+// Paragraph 对 s 运行段落级算法
+//
+// 不合法的 UTF-8 字节按 U+FFFD 处理。
+func (p *Properties) Paragraph(s string, dir Direction) *Paragraph {
+	runes := []rune(s)
+	para := &Paragraph{
+		props:   p,
+		runes:   runes,
+		initial: make([]Class, len(runes)),
+		types:   make([]Class, len(runes)),
+		levels:  make([]uint8, len(runes)),
+	}
+	for i, r := range runes {
+		para.initial[i] = p.Class(r)
+	}
+	copy(para.types, para.initial)
+	para.matchIsolates()
+
+	switch dir {
+	case LTR:
+		para.Level = 0
+	case RTL:
+		para.Level = 1
+	default:
+		para.Level = para.firstStrongLevel(0, len(runes), 0)
+	}
+
+	para.explicitLevels()
+	sequences := para.isolatingRunSequences()
+	for _, seq := range sequences {
+		seq.resolve()
+	}
+	para.assignRemovedLevels()
+	return para
+}
+
+// Len 段落的字符数
+func (para *Paragraph) Len() int {
+	return len(para.runes)
+}
+
+// Runes 段落的字符，按逻辑顺序
+func (para *Paragraph) Runes() []rune {
+	return para.runes
+}
+
+// isIsolateInitiator 是否为 LRI、RLI 或 FSI
+func isIsolateInitiator(c Class) bool {
+	return c == LRI || c == RLI || c == FSI
+}
+
+// removedByX9 X9 删除的字符：嵌入、覆盖、PDF 和 BN
+func removedByX9(c Class) bool {
+	switch c {
+	case LRE, RLE, LRO, RLO, PDF, BN:
+		return true
+	}
+	return false
+}
+
+// matchIsolates 按 BD9 匹配孤立起始符和 PDI，段落分隔符结束所有未匹配的孤立起始符
+func (para *Paragraph) matchIsolates() {
+	n := len(para.runes)
+	para.matchingPDI = make([]int, n)
+	para.matchingInitiator = make([]int, n)
+	var open []int
+	for i, c := range para.initial {
+		para.matchingPDI[i] = -1
+		para.matchingInitiator[i] = -1
+		switch {
+		case isIsolateInitiator(c):
+			open = append(open, i)
+		case c == PDI && len(open) > 0:
+			initiator := open[len(open)-1]
+			open = open[:len(open)-1]
+			para.matchingPDI[initiator] = i
+			para.matchingInitiator[i] = initiator
+		case c == B:
+			open = open[:0]
+		}
+	}
+}
+
+// firstStrongLevel 按 P2、P3 找 [start, end) 中孤立序列以外的第一个 L、R 或 AL，没有时返回 fallback
+func (para *Paragraph) firstStrongLevel(start, end int, fallback uint8) uint8 {
+	for i := start; i < end; i++ {
+		switch c := para.initial[i]; {
+		case c == L:
+			return 0
+		case c == R || c == AL:
+			return 1
+		case isIsolateInitiator(c):
+			if para.matchingPDI[i] < 0 {
+				return fallback
+			}
+			i = para.matchingPDI[i]
+		case c == B:
+			return fallback
+		}
+	}
+	return fallback
+}
+
+// status 方向状态栈的条目
+type status struct {
+	level    uint8
+	override Class // L、R，ON 表示没有覆盖
+	isolate  bool
+}
+
+// explicitLevels 按 X1–X8 计算显式嵌入层级
+func (para *Paragraph) explicitLevels() {
+	stack := make([]status, 1, maxDepth+2)
+	stack[0] = status{level: para.Level, override: ON}
+	overflowIsolates, overflowEmbeddings, validIsolates := 0, 0, 0
+
+	for i, c := range para.initial {
+		last := stack[len(stack)-1]
+		switch c {
+		case RLE, LRE, RLO, LRO, RLI, LRI, FSI:
+			isolate := isIsolateInitiator(c)
+			rtl := c == RLE || c == RLO || c == RLI
+			if c == FSI {
+				end := para.matchingPDI[i]
+				if end < 0 {
+					end = len(para.runes)
+				}
+				rtl = para.firstStrongLevel(i+1, end, 0) == 1
+			}
+
+			// X5a–X5c：孤立起始符本身使用外层的层级和覆盖
+			para.levels[i] = last.level
+			if isolate && last.override != ON {
+				para.types[i] = last.override
+			}
+
+			level := (last.level + 2) &^ 1 // 下一个偶数层级
+			if rtl {
+				level = (last.level + 1) | 1 // 下一个奇数层级
+			}
+
+			if level <= maxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				override := ON
+				switch c {
+				case LRO:
+					override = L
+				case RLO:
+					override = R
+				}
+				if isolate {
+					validIsolates++
+				}
+				stack = append(stack, status{level: level, override: override, isolate: isolate})
+			} else if isolate {
+				overflowIsolates++
+			} else if overflowIsolates == 0 {
+				overflowEmbeddings++
+			}
+
+		case PDI: // X6a
+			if overflowIsolates > 0 {
+				overflowIsolates--
+			} else if validIsolates > 0 {
+				overflowEmbeddings = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolates--
+			}
+			last = stack[len(stack)-1]
+			para.levels[i] = last.level
+			if last.override != ON {
+				para.types[i] = last.override
+			}
+
+		case PDF: // X7
+			if overflowIsolates > 0 {
+				// 不变
+			} else if overflowEmbeddings > 0 {
+				overflowEmbeddings--
+			} else if !last.isolate && len(stack) >= 2 {
+				stack = stack[:len(stack)-1]
+			}
+			para.levels[i] = last.level
+
+		case B: // X8
+			stack = stack[:1]
+			overflowIsolates, overflowEmbeddings, validIsolates = 0, 0, 0
+			para.levels[i] = para.Level
+
+		case BN:
+			para.levels[i] = last.level
+
+		default: // X6
+			para.levels[i] = last.level
+			if last.override != ON {
+				para.types[i] = last.override
+			}
+		}
+	}
+}
+
+// assignRemovedLevels X9 删除的字符取前一个字符的层级，在开头时取段落层级
+//
+// 这样 L1、L2 不会因为这些字符拆开层级相同的字符。
+func (para *Paragraph) assignRemovedLevels() {
+	for i, c := range para.initial {
+		if !removedByX9(c) {
+			continue
+		}
+		if i == 0 {
+			para.levels[i] = para.Level
+		} else {
+			para.levels[i] = para.levels[i-1]
+		}
+	}
+}
+
+// Levels 按 L1 调整后的层级，整个段落作为一行
+//
+// 段分隔符、段落分隔符，以及它们前面和行尾的空白和孤立格式字符恢复为段落层级。
+func (para *Paragraph) Levels() []uint8 {
+	levels := make([]uint8, len(para.levels))
+	copy(levels, para.levels)
+	trailing := true
+	for i := len(levels) - 1; i >= 0; i-- {
+		switch c := para.initial[i]; {
+		case c == S || c == B:
+			levels[i] = para.Level
+			trailing = true
+		case trailing && (c == WS || isIsolateInitiator(c) || c == PDI || removedByX9(c)):
+			levels[i] = para.Level
+		default:
+			trailing = false
+		}
+	}
+	return levels
+}
+
+// Removed 第 i 个字符是否被 X9 删除，这些字符的层级只是为了显示而指定的
+func (para *Paragraph) Removed(i int) bool {
+	return removedByX9(para.initial[i])
+}
+
+// Reorder 按 L2 返回显示顺序，结果的第 k 个元素是显示在第 k 个位置的字符下标
+func (para *Paragraph) Reorder() []int {
+	levels := para.Levels()
+	order := make([]int, len(levels))
+	var highest, lowestOdd uint8 = 0, maxDepth + 2
+	for i, level := range levels {
+		order[i] = i
+		highest = max(highest, level)
+		if level%2 == 1 {
+			lowestOdd = min(lowestOdd, level)
+		}
+	}
+
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(levels); {
+			if levels[order[i]] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(levels) && levels[order[j]] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
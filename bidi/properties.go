@@ -0,0 +1,297 @@
+package bidi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"udc2mongo/model"
+)
+
+// Class Bidi_Class 的取值
+//
+// See: https://www.unicode.org/reports/tr9/#Bidirectional_Character_Types
+type Class uint8
+
+const (
+	L Class = iota
+	R
+	AL
+	EN
+	ES
+	ET
+	AN
+	CS
+	NSM
+	BN
+	B
+	S
+	WS
+	ON
+	LRE
+	LRO
+	RLE
+	RLO
+	PDF
+	LRI
+	RLI
+	FSI
+	PDI
+)
+
+var classNames = [...]string{
+	L: "L", R: "R", AL: "AL",
+	EN: "EN", ES: "ES", ET: "ET", AN: "AN", CS: "CS", NSM: "NSM", BN: "BN",
+	B: "B", S: "S", WS: "WS", ON: "ON",
+	LRE: "LRE", LRO: "LRO", RLE: "RLE", RLO: "RLO", PDF: "PDF",
+	LRI: "LRI", RLI: "RLI", FSI: "FSI", PDI: "PDI",
+}
+
+// String 返回短名，例如 AL
+func (c Class) String() string {
+	if int(c) < len(classNames) {
+		return classNames[c]
+	}
+	return fmt.Sprintf("Class(%d)", uint8(c))
+}
+
+var classValues = map[string]Class{
+	"L":                       L,
+	"Left_To_Right":           L,
+	"R":                       R,
+	"Right_To_Left":           R,
+	"AL":                      AL,
+	"Arabic_Letter":           AL,
+	"EN":                      EN,
+	"European_Number":         EN,
+	"ES":                      ES,
+	"European_Separator":      ES,
+	"ET":                      ET,
+	"European_Terminator":     ET,
+	"AN":                      AN,
+	"Arabic_Number":           AN,
+	"CS":                      CS,
+	"Common_Separator":        CS,
+	"NSM":                     NSM,
+	"Nonspacing_Mark":         NSM,
+	"BN":                      BN,
+	"Boundary_Neutral":        BN,
+	"B":                       B,
+	"Paragraph_Separator":     B,
+	"S":                       S,
+	"Segment_Separator":       S,
+	"WS":                      WS,
+	"White_Space":             WS,
+	"ON":                      ON,
+	"Other_Neutral":           ON,
+	"LRE":                     LRE,
+	"Left_To_Right_Embedding": LRE,
+	"LRO":                     LRO,
+	"Left_To_Right_Override":  LRO,
+	"RLE":                     RLE,
+	"Right_To_Left_Embedding": RLE,
+	"RLO":                     RLO,
+	"Right_To_Left_Override":  RLO,
+	"PDF":                     PDF,
+	"Pop_Directional_Format":  PDF,
+	"LRI":                     LRI,
+	"Left_To_Right_Isolate":   LRI,
+	"RLI":                     RLI,
+	"Right_To_Left_Isolate":   RLI,
+	"FSI":                     FSI,
+	"First_Strong_Isolate":    FSI,
+	"PDI":                     PDI,
+	"Pop_Directional_Isolate": PDI,
+}
+
+// bracketType Bidi_Paired_Bracket_Type 的取值
+type bracketType uint8
+
+const (
+	bracketNone bracketType = iota
+	bracketOpen
+	bracketClose
+)
+
+var bracketTypeValues = map[string]bracketType{
+	"n":     bracketNone,
+	"None":  bracketNone,
+	"o":     bracketOpen,
+	"Open":  bracketOpen,
+	"c":     bracketClose,
+	"Close": bracketClose,
+}
+
+// bracket 成对括号的数据
+type bracket struct {
+	kind bracketType
+	pair rune // Bidi_Paired_Bracket
+}
+
+// span Bidi_Class 相同的连续字符点
+type span struct {
+	first, last rune
+	class       Class
+}
+
+// Properties 双向算法使用的属性表
+//
+// Bidi_Class 按字符点排序并合并相邻的相同取值，范围条目也包括在内，因为未分配的希伯来文、阿拉伯文区段有各自的默认值。
+// 表中没有的字符点取 L。
+type Properties struct {
+	spans []span
+
+	mirrors   map[rune]rune    // Bidi_M 为 Y 且有 bmg 的字符
+	brackets  map[rune]bracket // bpt 为 o 或 c 的字符
+	canonical map[rune]rune    // 括号的单字符规范分解，例如 2329 → 3008，匹配括号时视为同一字符
+}
+
+// NewProperties 从解析后的字符点建立属性表
+//
+// bc 和 bpt 可以是短名或全名，未知的取值返回错误。
+func NewProperties(codePoints []model.CodePoint) (*Properties, error) {
+	p := &Properties{
+		mirrors:   make(map[rune]rune),
+		brackets:  make(map[rune]bracket),
+		canonical: make(map[rune]rune),
+	}
+
+	spans := make([]span, 0, len(codePoints))
+	decompositions := make(map[rune]rune)
+	for i := range codePoints {
+		cp := &codePoints[i]
+		first, last, err := entrySpan(cp)
+		if err != nil {
+			return nil, err
+		}
+
+		class := L
+		if cp.BidiClass != "" {
+			var ok bool
+			if class, ok = classValues[cp.BidiClass]; !ok {
+				return nil, fmt.Errorf("%s: unknown bc value %q", model.FormatCodePoint(first), cp.BidiClass)
+			}
+		}
+		spans = append(spans, span{first: first, last: last, class: class})
+
+		if cp.CP == "" {
+			// 范围条目不是镜像字符，也不是括号
+			continue
+		}
+		r := first
+
+		if cp.BidiMirrored && cp.BidiMirroringGlyph != "" {
+			mirror, err := model.ParseCodePoint(cp.BidiMirroringGlyph)
+			if err != nil {
+				return nil, fmt.Errorf("%s: bmg: %w", cp.CP, err)
+			}
+			p.mirrors[r] = mirror
+		}
+
+		kind := bracketNone
+		if cp.BidiPairedBracketType != "" {
+			var ok bool
+			if kind, ok = bracketTypeValues[cp.BidiPairedBracketType]; !ok {
+				return nil, fmt.Errorf("%s: unknown bpt value %q", cp.CP, cp.BidiPairedBracketType)
+			}
+		}
+		if kind != bracketNone {
+			pair, err := model.ParseCodePoint(cp.BidiPairedBracket)
+			if err != nil {
+				return nil, fmt.Errorf("%s: bpb: %w", cp.CP, err)
+			}
+			p.brackets[r] = bracket{kind: kind, pair: pair}
+		}
+
+		if fields := strings.Fields(cp.DecompositionMapping); cp.DecompositionType == "can" && len(fields) == 1 {
+			d, err := model.ParseCodePoint(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s: dm: %w", cp.CP, err)
+			}
+			decompositions[r] = d
+		}
+	}
+
+	// 只保留括号的单字符规范分解
+	for r := range p.brackets {
+		if d, ok := decompositions[r]; ok {
+			p.canonical[r] = d
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].first < spans[j].first })
+	merged := spans[:0]
+	for _, s := range spans {
+		if n := len(merged); n > 0 {
+			prev := &merged[n-1]
+			if s.first <= prev.last {
+				return nil, fmt.Errorf("%s: overlaps %s..%s", model.FormatCodePoint(s.first),
+					model.FormatCodePoint(prev.first), model.FormatCodePoint(prev.last))
+			}
+			if s.first == prev.last+1 && s.class == prev.class {
+				prev.last = s.last
+				continue
+			}
+		}
+		merged = append(merged, s)
+	}
+	p.spans = merged
+	return p, nil
+}
+
+// Len 合并后的 Bidi_Class 条目数
+func (p *Properties) Len() int {
+	return len(p.spans)
+}
+
+// Class 字符点的 Bidi_Class
+func (p *Properties) Class(r rune) Class {
+	i := sort.Search(len(p.spans), func(i int) bool { return p.spans[i].last >= r })
+	if i < len(p.spans) && p.spans[i].first <= r {
+		return p.spans[i].class
+	}
+	return L
+}
+
+// Mirror 返回镜像字符（Bidi_Mirroring_Glyph），没有时返回 r 和 false
+//
+// Bidi_Mirrored 为 Y 但没有对应镜像字符的，例如 ∛，需要由字体显示镜像字形，这里也返回 false。
+func (p *Properties) Mirror(r rune) (rune, bool) {
+	if m, ok := p.mirrors[r]; ok {
+		return m, true
+	}
+	return r, false
+}
+
+// bracketKey 括号匹配使用的值：开括号为自身，闭括号为对应的开括号，都取规范分解
+func (p *Properties) bracketKey(r rune) (bracketType, rune) {
+	b, ok := p.brackets[r]
+	if !ok {
+		return bracketNone, 0
+	}
+	key := r
+	if b.kind == bracketClose {
+		key = b.pair
+	}
+	if d, ok := p.canonical[key]; ok {
+		key = d
+	}
+	return b.kind, key
+}
+
+// entrySpan 条目的范围，单个字符点时 first 等于 last
+func entrySpan(cp *model.CodePoint) (rune, rune, error) {
+	if cp.CP != "" {
+		r, err := model.ParseCodePoint(cp.CP)
+		return r, r, err
+	}
+	first, err := model.ParseCodePoint(cp.FirstCP)
+	if err != nil {
+		return 0, 0, err
+	}
+	last, err := model.ParseCodePoint(cp.LastCP)
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
@@ -0,0 +1,353 @@
+package bidi
+
+import "sort"
+
+// maxBracketDepth BD16 中开括号栈的上限
+const maxBracketDepth = 63
+
+// sequence 孤立层级序列（BD13），由一个或多个层级相同的层级序列经孤立起始符和对应的 PDI 连接而成
+type sequence struct {
+	para     *Paragraph
+	indexes  []int   // 段落中的下标，不包括 X9 删除的字符
+	types    []Class // 与 indexes 对应的类型
+	level    uint8
+	sos, eos Class // L 或 R
+}
+
+// isolatingRunSequences 按 X10 把 X9 之后剩下的字符分成孤立层级序列，并确定 sos 和 eos
+//
+// sos、eos 取决于相邻字符的显式层级，必须在解析任何序列之前全部计算。
+func (para *Paragraph) isolatingRunSequences() []*sequence {
+	var runs [][]int
+	runOf := make([]int, len(para.runes))
+	lastIndex := -1
+	for i, c := range para.initial {
+		if removedByX9(c) {
+			continue
+		}
+		if lastIndex < 0 || para.levels[i] != para.levels[lastIndex] {
+			runs = append(runs, nil)
+		}
+		runs[len(runs)-1] = append(runs[len(runs)-1], i)
+		runOf[i] = len(runs) - 1
+		lastIndex = i
+	}
+
+	// 以孤立起始符结尾的层级序列，接上以对应 PDI 开头的层级序列
+	next := make([]int, len(runs))
+	continued := make([]bool, len(runs))
+	for k, run := range runs {
+		next[k] = -1
+		last := run[len(run)-1]
+		if pdi := para.matchingPDI[last]; isIsolateInitiator(para.initial[last]) && pdi >= 0 {
+			if target := runOf[pdi]; runs[target][0] == pdi {
+				next[k] = target
+				continued[target] = true
+			}
+		}
+	}
+
+	var sequences []*sequence
+	for k := range runs {
+		if continued[k] {
+			continue
+		}
+		var indexes []int
+		for j := k; j >= 0; j = next[j] {
+			indexes = append(indexes, runs[j]...)
+		}
+		sequences = append(sequences, para.newSequence(indexes))
+	}
+	return sequences
+}
+
+// newSequence 计算序列的 sos 和 eos
+func (para *Paragraph) newSequence(indexes []int) *sequence {
+	seq := &sequence{
+		para:    para,
+		indexes: indexes,
+		types:   make([]Class, len(indexes)),
+		level:   para.levels[indexes[0]],
+	}
+	for k, i := range indexes {
+		seq.types[k] = para.types[i]
+	}
+
+	before := para.Level
+	for i := indexes[0] - 1; i >= 0; i-- {
+		if !removedByX9(para.initial[i]) {
+			before = para.levels[i]
+			break
+		}
+	}
+
+	last := indexes[len(indexes)-1]
+	after := para.Level
+	if !isIsolateInitiator(para.initial[last]) {
+		for i := last + 1; i < len(para.runes); i++ {
+			if !removedByX9(para.initial[i]) {
+				after = para.levels[i]
+				break
+			}
+		}
+	}
+
+	seq.sos = directionOf(max(before, seq.level))
+	seq.eos = directionOf(max(after, seq.level))
+	return seq
+}
+
+// directionOf 层级对应的方向，奇数为 R，偶数为 L
+func directionOf(level uint8) Class {
+	if level%2 == 1 {
+		return R
+	}
+	return L
+}
+
+// resolve 按 W1–W7、N0–N2、I1–I2 解析序列中字符的层级
+func (seq *sequence) resolve() {
+	seq.resolveWeakTypes()
+	seq.resolvePairedBrackets()
+	seq.resolveNeutralTypes()
+
+	for k, i := range seq.indexes {
+		level := seq.para.levels[i]
+		switch t := seq.types[k]; {
+		case level%2 == 0 && t == R: // I1
+			level++
+		case level%2 == 0 && (t == AN || t == EN):
+			level += 2
+		case level%2 == 1 && (t == L || t == EN || t == AN): // I2
+			level++
+		}
+		seq.para.levels[i] = level
+	}
+}
+
+// resolveWeakTypes W1–W7
+func (seq *sequence) resolveWeakTypes() {
+	types := seq.types
+
+	// W1：NSM 取前一个字符的类型，前一个是孤立起始符或 PDI 时为 ON
+	for k, t := range types {
+		if t != NSM {
+			continue
+		}
+		switch {
+		case k == 0:
+			types[k] = seq.sos
+		case isIsolateInitiator(types[k-1]) || types[k-1] == PDI:
+			types[k] = ON
+		default:
+			types[k] = types[k-1]
+		}
+	}
+
+	// W2、W3：AL 之后的 EN 改为 AN，然后 AL 改为 R
+	strong := seq.sos
+	for k, t := range types {
+		switch t {
+		case L, R, AL:
+			strong = t
+		case EN:
+			if strong == AL {
+				types[k] = AN
+			}
+		}
+	}
+	for k, t := range types {
+		if t == AL {
+			types[k] = R
+		}
+	}
+
+	// W4：两个 EN 之间的单个 ES，两个相同数字类型之间的单个 CS
+	for k := 1; k+1 < len(types); k++ {
+		prev, next := types[k-1], types[k+1]
+		switch {
+		case types[k] == ES && prev == EN && next == EN:
+			types[k] = EN
+		case types[k] == CS && prev == next && (prev == EN || prev == AN):
+			types[k] = prev
+		}
+	}
+
+	// W5：与 EN 相邻的连续 ET 改为 EN
+	for k := 0; k < len(types); {
+		if types[k] != ET {
+			k++
+			continue
+		}
+		end := k
+		for end < len(types) && types[end] == ET {
+			end++
+		}
+		if (k > 0 && types[k-1] == EN) || (end < len(types) && types[end] == EN) {
+			for j := k; j < end; j++ {
+				types[j] = EN
+			}
+		}
+		k = end
+	}
+
+	// W6：其余分隔符和终止符改为 ON
+	for k, t := range types {
+		if t == ES || t == ET || t == CS {
+			types[k] = ON
+		}
+	}
+
+	// W7：前面最近的强类型是 L 时，EN 改为 L
+	strong = seq.sos
+	for k, t := range types {
+		switch t {
+		case L, R:
+			strong = t
+		case EN:
+			if strong == L {
+				types[k] = L
+			}
+		}
+	}
+}
+
+// bracketPair 序列中一对括号的位置
+type bracketPair struct {
+	open, close int
+}
+
+// locateBrackets 按 BD16 找出序列中的成对括号，按开括号的位置排序
+//
+// 只匹配当前类型为 ON 的括号，经过覆盖的括号不参与匹配。
+func (seq *sequence) locateBrackets() []bracketPair {
+	type opener struct {
+		position int
+		key      rune
+	}
+	var stack []opener
+	var pairs []bracketPair
+	for k, i := range seq.indexes {
+		if seq.types[k] != ON {
+			continue
+		}
+		kind, key := seq.para.props.bracketKey(seq.para.runes[i])
+		switch kind {
+		case bracketOpen:
+			if len(stack) == maxBracketDepth {
+				// 栈满时停止处理这个序列余下的部分
+				return sortPairs(pairs)
+			}
+			stack = append(stack, opener{position: k, key: key})
+		case bracketClose:
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j].key == key {
+					pairs = append(pairs, bracketPair{open: stack[j].position, close: k})
+					stack = stack[:j]
+					break
+				}
+			}
+		}
+	}
+	return sortPairs(pairs)
+}
+
+func sortPairs(pairs []bracketPair) []bracketPair {
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].open < pairs[j].open })
+	return pairs
+}
+
+// strongN0 N0 使用的强类型，EN 和 AN 视为 R，其他类型返回 ON
+func strongN0(t Class) Class {
+	switch t {
+	case L:
+		return L
+	case R, EN, AN:
+		return R
+	}
+	return ON
+}
+
+// resolvePairedBrackets N0：按括号内外的强类型确定成对括号的方向
+func (seq *sequence) resolvePairedBrackets() {
+	embedding := directionOf(seq.level)
+	for _, pair := range seq.locateBrackets() {
+		// N0 b–d：括号内有与嵌入方向相同的强类型时取嵌入方向；只有相反方向时看括号前的上下文
+		inside := ON
+		for k := pair.open + 1; k < pair.close; k++ {
+			if t := strongN0(seq.types[k]); t != ON {
+				inside = t
+				if t == embedding {
+					break
+				}
+			}
+		}
+		if inside == ON {
+			continue
+		}
+
+		direction := embedding
+		if inside != embedding {
+			before := seq.sos
+			for k := pair.open - 1; k >= 0; k-- {
+				if t := strongN0(seq.types[k]); t != ON {
+					before = t
+					break
+				}
+			}
+			if before == inside {
+				direction = inside
+			}
+		}
+
+		seq.types[pair.open] = direction
+		seq.types[pair.close] = direction
+		// 括号后面原本是 NSM 的字符在 W1 中变成了 ON，改为与括号相同
+		for _, position := range []int{pair.open, pair.close} {
+			for k := position + 1; k < len(seq.indexes) && seq.para.initial[seq.indexes[k]] == NSM; k++ {
+				seq.types[k] = direction
+			}
+		}
+	}
+}
+
+// isNeutral N1、N2 中的中性字符和孤立格式字符
+func isNeutral(t Class) bool {
+	switch t {
+	case B, S, WS, ON, LRI, RLI, FSI, PDI:
+		return true
+	}
+	return false
+}
+
+// resolveNeutralTypes N1、N2：中性字符两侧方向相同时取该方向，否则取嵌入方向
+func (seq *sequence) resolveNeutralTypes() {
+	types := seq.types
+	embedding := directionOf(seq.level)
+	for k := 0; k < len(types); {
+		if !isNeutral(types[k]) {
+			k++
+			continue
+		}
+		end := k
+		for end < len(types) && isNeutral(types[end]) {
+			end++
+		}
+
+		before, after := seq.sos, seq.eos
+		if k > 0 {
+			before = strongN0(types[k-1])
+		}
+		if end < len(types) {
+			after = strongN0(types[end])
+		}
+		direction := embedding
+		if before == after {
+			direction = before
+		}
+		for j := k; j < end; j++ {
+			types[j] = direction
+		}
+		k = end
+	}
+}
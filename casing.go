@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"udc2mongo/casing"
+	"udc2mongo/model"
+)
+
+// runCase 用导入版本的大小写映射转换文本
+func runCase(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("case", flag.ExitOnError)
+	source := flags.String("source", "xml", "read from the UCD XML (xml) or from MONGODB_DB (mongo)")
+	opName := flags.String("op", "upper", "upper, lower, title or fold")
+	lang := flags.String("lang", "", "language tag; tr, az and lt have special mappings")
+	flags.Parse(args)
+
+	op, err := casing.ParseOperation(*opName)
+	if err != nil {
+		return err
+	}
+	language := casing.ParseLanguage(*lang)
+
+	text := strings.Join(flags.Args(), " ")
+	if text == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading standard input: %w", err)
+		}
+		text = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	}
+	if text == "" {
+		return fmt.Errorf("usage: case [-op upper|lower|title|fold] [-lang tag] <text>, or pipe the text to standard input")
+	}
+
+	var version string
+	var codePoints []model.CodePoint
+	switch *source {
+	case "xml":
+		version = ucdVersion()
+		codePoints, _, err = loadFromXML(ctx, version)
+	case "mongo":
+		version, codePoints, _, err = loadFromMongo(ctx, mongoDBName())
+	default:
+		err = fmt.Errorf("unknown source %q", *source)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading data: %w", err)
+	}
+
+	mapper, err := loadCaseMapper(ctx, version, codePoints, language != casing.Default)
+	if err != nil {
+		return err
+	}
+
+	result, err := mapper.Apply(op, text, language)
+	if err != nil {
+		return err
+	}
+	fmt.Println(result)
+	return nil
+}
+
+// loadCaseMapper 建立大小写映射表，并加载同一版本的 SpecialCasing.txt 和 CaseFolding.txt
+//
+// 条件映射是附加数据，获取失败时只记录警告，Final_Sigma 和语言相关的映射不可用；required 为 true 时返回错误。
+func loadCaseMapper(ctx context.Context, version string, codePoints []model.CodePoint, required bool) (*casing.Mapper, error) {
+	mapper, err := casing.NewMapper(codePoints)
+	if err != nil {
+		return nil, fmt.Errorf("error building case mappings: %w", err)
+	}
+	if !mapper.HasSoftDotted() {
+		slog.Warn("no code point is Soft_Dotted, the data may predate the soft_dotted field; re-import it for correct Lithuanian mappings")
+	}
+
+	var contents [][]byte
+	for _, fileName := range []string{"SpecialCasing.txt", "CaseFolding.txt"} {
+		var content []byte
+		content, _, err = fetchUcdTextWithCache(ctx, version, fileName)
+		if err != nil {
+			break
+		}
+		contents = append(contents, content)
+	}
+	if err == nil {
+		err = mapper.LoadSpecialCasing(bytes.NewReader(contents[0]), bytes.NewReader(contents[1]))
+	}
+	if err != nil {
+		if required || ctx.Err() != nil {
+			return nil, fmt.Errorf("error loading special casing: %w", err)
+		}
+		slog.Warn("skipping conditional case mappings", "error", err)
+	}
+	return mapper, nil
+}
@@ -0,0 +1,177 @@
+// Package casing 按 Unicode 第 3.13 节转换大小写和折叠大小写
+//
+// 映射表从导入的 UCD 建立，结果与导入的 Unicode 版本一致。
+//
+// See: https://www.unicode.org/versions/latest/core-spec/chapter-3/#G33992
+package casing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Language 影响大小写映射的语言，只有 SpecialCasing.txt 中出现的语言有区别
+type Language string
+
+const (
+	Default     Language = ""
+	Turkish     Language = "tr"
+	Azerbaijani Language = "az"
+	Lithuanian  Language = "lt"
+)
+
+// ParseLanguage 取 BCP 47 标签的主语言，例如 tr-TR 为 Turkish，没有特殊映射的语言为 Default
+func ParseLanguage(s string) Language {
+	primary, _, _ := strings.Cut(strings.ReplaceAll(s, "_", "-"), "-")
+	switch l := Language(strings.ToLower(primary)); l {
+	case Turkish, Azerbaijani, Lithuanian:
+		return l
+	}
+	return Default
+}
+
+// turkic 是否使用土耳其语的 I 和 i
+func (l Language) turkic() bool {
+	return l == Turkish || l == Azerbaijani
+}
+
+// Operation 大小写操作
+type Operation string
+
+const (
+	Upper Operation = "upper"
+	Lower Operation = "lower"
+	Title Operation = "title"
+	Fold  Operation = "fold"
+)
+
+// ParseOperation 解析大小写操作
+func ParseOperation(s string) (Operation, error) {
+	switch op := Operation(strings.ToLower(s)); op {
+	case Upper, Lower, Title, Fold:
+		return op, nil
+	}
+	return "", fmt.Errorf("unknown operation %q, expected upper, lower, title or fold", s)
+}
+
+// Apply 对 s 执行 op，未知的操作返回错误
+func (m *Mapper) Apply(op Operation, s string, language Language) (string, error) {
+	switch op {
+	case Upper:
+		return m.ToUpper(s, language), nil
+	case Lower:
+		return m.ToLower(s, language), nil
+	case Title:
+		return m.ToTitle(s, language), nil
+	case Fold:
+		return m.Fold(s, language), nil
+	}
+	return "", fmt.Errorf("unknown operation %q", op)
+}
+
+// ToUpper 使用完整映射转换为大写
+func (m *Mapper) ToUpper(s string, language Language) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i := range runes {
+		m.writeMapping(&sb, Upper, runes, i, language)
+	}
+	return sb.String()
+}
+
+// ToLower 使用完整映射转换为小写，包括 Final_Sigma
+func (m *Mapper) ToLower(s string, language Language) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i := range runes {
+		m.writeMapping(&sb, Lower, runes, i, language)
+	}
+	return sb.String()
+}
+
+// ToTitle 按 UAX #29 的单词边界，把每个单词的第一个 Cased 字符转换为标题大小写，其余字符转换为小写
+func (m *Mapper) ToTitle(s string, language Language) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	i := 0
+	for _, word := range m.words.Words(s) {
+		end := i + len([]rune(word))
+		op := Title
+		for ; i < end; i++ {
+			if op == Title && m.entry(runes[i]).cased {
+				m.writeMapping(&sb, Title, runes, i, language)
+				op = Lower
+				continue
+			}
+			if op == Title {
+				sb.WriteRune(runes[i])
+				continue
+			}
+			m.writeMapping(&sb, Lower, runes, i, language)
+		}
+	}
+	return sb.String()
+}
+
+// Fold 使用完整折叠，土耳其语和阿塞拜疆语使用 CaseFolding.txt 中状态为 T 的映射
+func (m *Mapper) Fold(s string, language Language) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if language.turkic() {
+			if fold, ok := m.turkicFold[r]; ok {
+				writeRunes(&sb, fold)
+				continue
+			}
+		}
+		if fold := m.entry(r).fold; fold != nil {
+			writeRunes(&sb, fold)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// writeMapping 写入第 i 个字符的映射，条件映射优先
+func (m *Mapper) writeMapping(sb *strings.Builder, op Operation, runes []rune, i int, language Language) {
+	r := runes[i]
+	for _, c := range m.special[r] {
+		if m.matches(&c, language, runes, i) {
+			writeRunes(sb, c.mapping(op))
+			return
+		}
+	}
+
+	e := m.entry(r)
+	var mapping []rune
+	switch op {
+	case Upper:
+		mapping = e.upper
+	case Lower:
+		mapping = e.lower
+	case Title:
+		mapping = e.title
+	}
+	if mapping == nil {
+		sb.WriteRune(r)
+		return
+	}
+	writeRunes(sb, mapping)
+}
+
+// mapping 条件映射中 op 对应的映射
+func (c *conditional) mapping(op Operation) []rune {
+	switch op {
+	case Upper:
+		return c.upper
+	case Title:
+		return c.title
+	}
+	return c.lower
+}
+
+func writeRunes(sb *strings.Builder, runes []rune) {
+	for _, r := range runes {
+		sb.WriteRune(r)
+	}
+}
@@ -0,0 +1,175 @@
+package casing
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+
+	"udc2mongo/model"
+)
+
+// testdata 是 Unicode 14.0.0 的数据：SpecialCasing.txt 是 Perl 5.36 附带的官方文件；
+// ucd.xml 和 CaseFolding.txt 只有拉丁文、希腊文、阿德拉姆文等部分字符，由 Perl 的 Unicode::UCD 生成；
+// CaseTest.txt 是 Python 3.11 的 str.upper、str.lower 和 str.casefold 的结果，作为不限语言时的期望值。
+
+func loadMapper(t *testing.T) *Mapper {
+	t.Helper()
+	data, err := os.ReadFile("testdata/ucd.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ucd, err := model.ParseUCDXML(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codePoints := model.ExtractAllCodePoints(ucd)
+	for i := range codePoints {
+		model.NormalizeCodePoint(&codePoints[i])
+	}
+	m, err := NewMapper(codePoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	special, err := os.Open("testdata/SpecialCasing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer special.Close()
+	folding, err := os.Open("testdata/CaseFolding.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer folding.Close()
+	if err := m.LoadSpecialCasing(special, folding); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// parseHex 解析以空格分隔的十六进制字符点
+func parseHex(t *testing.T, s string) string {
+	t.Helper()
+	runes, err := parseRunes(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(runes)
+}
+
+func TestCaseTest(t *testing.T) {
+	m := loadMapper(t)
+	if !m.HasSoftDotted() {
+		t.Error("HasSoftDotted() = false")
+	}
+
+	f, err := os.Open("testdata/CaseTest.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) != 4 {
+			t.Fatalf("line %d: expected 4 fields, got %d", lineNo, len(fields))
+		}
+		lines++
+		s := parseHex(t, fields[0])
+		for i, op := range []Operation{Upper, Lower, Fold} {
+			want := parseHex(t, fields[i+1])
+			got, err := m.Apply(op, s, Default)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("line %d: %s(%+q) = %+q, want %+q", lineNo, op, s, got, want)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if lines == 0 {
+		t.Fatal("no test lines")
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	m := loadMapper(t)
+	tests := []struct {
+		op       Operation
+		language Language
+		in       string
+		want     string
+	}{
+		{Upper, Turkish, "istanbul \u0131i", "\u0130STANBUL I\u0130"},
+		{Lower, Turkish, "\u0130STANBUL I", "istanbul \u0131"},
+		{Lower, Turkish, "I\u0307", "i"},
+		{Lower, Azerbaijani, "\u0130I", "i\u0131"},
+		{Fold, Turkish, "I\u0130", "\u0131i"},
+		{Fold, Default, "I\u0130", "ii\u0307"},
+		{Lower, Lithuanian, "I\u0300", "i\u0307\u0300"},
+		{Lower, Lithuanian, "\u00CC", "i\u0307\u0300"},
+		{Lower, Lithuanian, "J\u0301", "j\u0307\u0301"},
+		{Upper, Lithuanian, "i\u0307", "I"},
+		{Upper, Default, "i\u0307", "I\u0307"},
+		{Title, Default, "hello wORLD", "Hello World"},
+		{Title, Default, "\u01C6emal", "\u01C5emal"},
+		{Title, Default, "\uFB01sh", "Fish"},
+		{Title, Default, "'twas", "'Twas"},
+		{Title, Turkish, "istanbul", "\u0130stanbul"},
+		{Title, Default, "\u03A3\u0391\u03A3", "\u03A3\u03B1\u03C2"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.op)+"/"+string(tt.language)+"/"+tt.in, func(t *testing.T) {
+			got, err := m.Apply(tt.op, tt.in, tt.language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply(%s, %q, %q) = %q, want %q", tt.op, tt.in, tt.language, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyUnknownOperation(t *testing.T) {
+	m := loadMapper(t)
+	if _, err := m.Apply("swap", "a", Default); err == nil {
+		t.Error("Apply(swap) succeeded")
+	}
+}
+
+func TestParse(t *testing.T) {
+	languages := []struct {
+		in   string
+		want Language
+	}{
+		{"tr", Turkish},
+		{"tr-TR", Turkish},
+		{"AZ_Latn", Azerbaijani},
+		{"lt", Lithuanian},
+		{"en-US", Default},
+		{"", Default},
+	}
+	for _, tt := range languages {
+		if got := ParseLanguage(tt.in); got != tt.want {
+			t.Errorf("ParseLanguage(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if op, err := ParseOperation("Upper"); err != nil || op != Upper {
+		t.Errorf("ParseOperation(Upper) = %q, %v", op, err)
+	}
+	if _, err := ParseOperation("swap"); err == nil {
+		t.Error("ParseOperation(swap) succeeded")
+	}
+}
@@ -0,0 +1,132 @@
+package casing
+
+import (
+	"fmt"
+	"strings"
+
+	"udc2mongo/model"
+	"udc2mongo/segment"
+)
+
+// entry 单个字符点的大小写数据，映射为 nil 时映射到自身
+type entry struct {
+	upper, lower, title, fold []rune
+
+	cased         bool
+	caseIgnorable bool
+	softDotted    bool
+	ccc           uint8
+}
+
+// Mapper 从导入的 UCD 建立的大小写映射表
+//
+// 无条件的完整映射来自 uc、lc、tc 和 cf，没有时使用 suc、slc、stc 和 scf。
+// Final_Sigma 以及土耳其语、阿塞拜疆语和立陶宛语的条件映射不在 UCD XML 中，由 LoadSpecialCasing 加载。
+type Mapper struct {
+	entries map[rune]*entry
+	words   *segment.Properties // ToTitle 使用的单词边界
+
+	special    map[rune][]conditional // SpecialCasing.txt 的条件映射
+	turkicFold map[rune][]rune        // CaseFolding.txt 中状态为 T 的映射
+	softDotted bool                   // 是否有 Soft_Dotted 的字符点
+}
+
+// NewMapper 从解析后的字符点建立映射表
+func NewMapper(codePoints []model.CodePoint) (*Mapper, error) {
+	words, err := segment.NewProperties(codePoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build word break properties: %w", err)
+	}
+
+	m := &Mapper{entries: make(map[rune]*entry), words: words}
+	for i := range codePoints {
+		cp := &codePoints[i]
+		if cp.CP == "" {
+			// 范围条目没有大小写映射，也不是 Cased 或 Case_Ignorable
+			continue
+		}
+		r, err := model.ParseCodePoint(cp.CP)
+		if err != nil {
+			return nil, err
+		}
+
+		e := entry{
+			cased:         bool(cp.Cased),
+			caseIgnorable: bool(cp.CaseIgnorable),
+			softDotted:    bool(cp.SoftDotted),
+		}
+		if cp.CombiningClass < 0 || cp.CombiningClass > 254 {
+			return nil, fmt.Errorf("%s: invalid ccc %d", cp.CP, cp.CombiningClass)
+		}
+		e.ccc = uint8(cp.CombiningClass)
+
+		mappings := []struct {
+			target       *[]rune
+			full, simple string
+		}{
+			{&e.upper, cp.UppercaseMapping, cp.SimpleUppercase},
+			{&e.lower, cp.LowercaseMapping, cp.SimpleLowercase},
+			{&e.title, cp.TitlecaseMapping, cp.SimpleTitlecase},
+			{&e.fold, cp.CaseFolding, cp.SimpleCaseFolding},
+		}
+		for _, mapping := range mappings {
+			value := mapping.full
+			if value == "" {
+				value = mapping.simple
+			}
+			runes, err := parseRunes(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", cp.CP, err)
+			}
+			if len(runes) != 1 || runes[0] != r {
+				*mapping.target = runes
+			}
+		}
+
+		m.softDotted = m.softDotted || e.softDotted
+		if e.upper != nil || e.lower != nil || e.title != nil || e.fold != nil ||
+			e.cased || e.caseIgnorable || e.softDotted || e.ccc != 0 {
+			m.entries[r] = &e
+		}
+	}
+	return m, nil
+}
+
+// HasSoftDotted 是否有 Soft_Dotted 的字符点
+//
+// 早于 soft_dotted 字段的导入中没有这个属性，立陶宛语的映射不会在 i、j 后保留或添加上方的点。
+func (m *Mapper) HasSoftDotted() bool {
+	return m.softDotted
+}
+
+// HasSpecialCasing 是否已加载条件映射
+func (m *Mapper) HasSpecialCasing() bool {
+	return m.special != nil
+}
+
+// parseRunes 解析以空格分隔的十六进制字符点
+func parseRunes(s string) ([]rune, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	runes := make([]rune, 0, len(fields))
+	for _, field := range fields {
+		r, err := model.ParseCodePoint(field)
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, r)
+	}
+	return runes, nil
+}
+
+// noEntry 表中没有的字符点，只读
+var noEntry entry
+
+func (m *Mapper) entry(r rune) *entry {
+	if e := m.entries[r]; e != nil {
+		return e
+	}
+	return &noEntry
+}
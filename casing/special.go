@@ -0,0 +1,199 @@
+package casing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"udc2mongo/model"
+)
+
+// conditional SpecialCasing.txt 中带条件的映射，空映射表示删除该字符
+//
+// See: https://www.unicode.org/Public/UCD/latest/ucd/SpecialCasing.txt
+type conditional struct {
+	lower, title, upper []rune
+	language            Language // 为空时不限语言
+	context             string   // 例如 Final_Sigma、More_Above，为空时没有上下文条件
+	negated             bool     // Not_Before_Dot 等
+}
+
+// languagePattern 条件列表中的语言 ID，上下文条件以大写字母开头
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// LoadSpecialCasing 加载 SpecialCasing.txt 的条件映射和 CaseFolding.txt 中土耳其语的折叠（状态 T）
+//
+// 无条件的映射已经在 UCD XML 中，这里跳过。
+func (m *Mapper) LoadSpecialCasing(specialCasing, caseFolding io.Reader) error {
+	special := make(map[rune][]conditional)
+	err := eachDataLine(specialCasing, func(lineNo int, fields []string) error {
+		if len(fields) < 5 || fields[4] == "" {
+			return nil
+		}
+		r, err := model.ParseCodePoint(fields[0])
+		if err != nil {
+			return fmt.Errorf("SpecialCasing.txt line %d: %w", lineNo, err)
+		}
+
+		var c conditional
+		for i, target := range []*[]rune{&c.lower, &c.title, &c.upper} {
+			runes, err := parseRunes(fields[i+1])
+			if err != nil {
+				return fmt.Errorf("SpecialCasing.txt line %d: %w", lineNo, err)
+			}
+			*target = append([]rune{}, runes...)
+		}
+		for _, condition := range strings.Fields(fields[4]) {
+			switch {
+			case languagePattern.MatchString(condition):
+				c.language = Language(condition)
+			case strings.HasPrefix(condition, "Not_"):
+				c.context, c.negated = strings.TrimPrefix(condition, "Not_"), true
+			default:
+				c.context = condition
+			}
+		}
+		if c.context != "" && !knownContexts[c.context] {
+			return fmt.Errorf("SpecialCasing.txt line %d: unknown condition %q", lineNo, fields[4])
+		}
+
+		// 限定语言的映射优先于不限语言的映射
+		if c.language != "" {
+			special[r] = append([]conditional{c}, special[r]...)
+		} else {
+			special[r] = append(special[r], c)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	turkicFold := make(map[rune][]rune)
+	err = eachDataLine(caseFolding, func(lineNo int, fields []string) error {
+		if len(fields) < 3 || fields[1] != "T" {
+			return nil
+		}
+		r, err := model.ParseCodePoint(fields[0])
+		if err != nil {
+			return fmt.Errorf("CaseFolding.txt line %d: %w", lineNo, err)
+		}
+		runes, err := parseRunes(fields[2])
+		if err != nil {
+			return fmt.Errorf("CaseFolding.txt line %d: %w", lineNo, err)
+		}
+		turkicFold[r] = runes
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m.special = special
+	m.turkicFold = turkicFold
+	return nil
+}
+
+// eachDataLine 按分号拆分每个数据行，去掉注释和字段两端的空白
+func eachDataLine(r io.Reader, fn func(lineNo int, fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if err := fn(lineNo, fields); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// knownContexts SpecialCasing.txt 使用的上下文条件
+//
+// See: https://www.unicode.org/versions/latest/core-spec/chapter-3/#G54277
+var knownContexts = map[string]bool{
+	"Final_Sigma":       true,
+	"After_Soft_Dotted": true,
+	"More_Above":        true,
+	"Before_Dot":        true,
+	"After_I":           true,
+}
+
+// matches 第 i 个字符是否满足条件
+func (m *Mapper) matches(c *conditional, language Language, runes []rune, i int) bool {
+	if c.language != "" && c.language != language {
+		return false
+	}
+	if c.context == "" {
+		return true
+	}
+
+	var ok bool
+	switch c.context {
+	case "Final_Sigma":
+		ok = m.finalSigma(runes, i)
+	case "After_Soft_Dotted":
+		ok = m.after(runes, i, func(r rune, e *entry) bool { return e.softDotted })
+	case "After_I":
+		ok = m.after(runes, i, func(r rune, e *entry) bool { return r == 'I' })
+	case "More_Above":
+		ok = m.before(runes, i, func(r rune, e *entry) bool { return e.ccc == 230 })
+	case "Before_Dot":
+		ok = m.before(runes, i, func(r rune, e *entry) bool { return r == '\u0307' })
+	}
+	return ok != c.negated
+}
+
+// finalSigma 前面是 Cased 字符和零个或多个 Case_Ignorable 字符，后面不是零个或多个 Case_Ignorable 字符和 Cased 字符
+func (m *Mapper) finalSigma(runes []rune, i int) bool {
+	j := i - 1
+	for j >= 0 && m.entry(runes[j]).caseIgnorable {
+		j--
+	}
+	if j < 0 || !m.entry(runes[j]).cased {
+		return false
+	}
+
+	j = i + 1
+	for j < len(runes) && m.entry(runes[j]).caseIgnorable {
+		j++
+	}
+	return j == len(runes) || !m.entry(runes[j]).cased
+}
+
+// after 前面有满足 match 的字符，中间没有 ccc 为 0 或 230 的字符
+func (m *Mapper) after(runes []rune, i int, match func(rune, *entry) bool) bool {
+	for j := i - 1; j >= 0; j-- {
+		e := m.entry(runes[j])
+		if match(runes[j], e) {
+			return true
+		}
+		if e.ccc == 0 || e.ccc == 230 {
+			return false
+		}
+	}
+	return false
+}
+
+// before 后面有满足 match 的字符，中间没有 ccc 为 0 或 230 的字符
+func (m *Mapper) before(runes []rune, i int, match func(rune, *entry) bool) bool {
+	for j := i + 1; j < len(runes); j++ {
+		e := m.entry(runes[j])
+		if match(runes[j], e) {
+			return true
+		}
+		if e.ccc == 0 || e.ccc == 230 {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"udc2mongo/model"
+)
+
+// DefaultProperties 默认生成的属性，使用 bson 字段名
+var DefaultProperties = []string{"general_category", "east_asian_width", "line_break", "script"}
+
+// Options 生成选项
+type Options struct {
+	Package    string
+	Version    string
+	Properties []string
+}
+
+// Output 生成的源代码
+type Output struct {
+	Source []byte // 查找表和查找函数
+	Test   []byte // 对应的测试
+}
+
+// valueRange 值相同的连续字符点范围
+type valueRange struct {
+	lo, hi rune
+	value  string
+}
+
+// property 单个属性的全部范围
+type property struct {
+	name   string // bson 字段名
+	goName string
+	ranges []valueRange
+	values []string
+}
+
+// Generate 为选定的属性生成压缩的范围表和查找函数
+func Generate(codePoints []model.CodePoint, opts Options) (*Output, error) {
+	if len(opts.Properties) == 0 {
+		opts.Properties = DefaultProperties
+	}
+
+	properties := make([]*property, 0, len(opts.Properties))
+	for _, name := range opts.Properties {
+		p, err := buildProperty(codePoints, name)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, p)
+	}
+
+	source, err := format.Source(generateSource(properties, opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated source: %w", err)
+	}
+
+	test, err := format.Source(generateTest(properties, opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated test: %w", err)
+	}
+
+	return &Output{Source: source, Test: test}, nil
+}
+
+// buildProperty 收集属性值并合并连续的相同值
+func buildProperty(codePoints []model.CodePoint, name string) (*property, error) {
+	index := -1
+	for i, v := range model.PropertyValues(&model.CodePointProperties{}) {
+		if v.Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("unknown property %q", name)
+	}
+
+	var ranges []valueRange
+	for i := range codePoints {
+		cp := &codePoints[i]
+		value := model.PropertyValues(&cp.CodePointProperties)[index].Value
+
+		first, last := cp.CP, cp.CP
+		if first == "" {
+			first, last = cp.FirstCP, cp.LastCP
+		}
+		lo, err := model.ParseCodePoint(first)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := model.ParseCodePoint(last)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, valueRange{lo, hi, value})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].lo < ranges[j].lo })
+
+	merged := make([]valueRange, 0, len(ranges))
+	seen := make(map[string]bool)
+	var values []string
+	for _, r := range ranges {
+		if !seen[r.value] {
+			seen[r.value] = true
+			values = append(values, r.value)
+		}
+		if n := len(merged); n > 0 && merged[n-1].value == r.value && merged[n-1].hi+1 == r.lo {
+			merged[n-1].hi = r.hi
+			continue
+		}
+		merged = append(merged, r)
+	}
+	sort.Strings(values)
+
+	return &property{
+		name:   name,
+		goName: goIdentifier(name),
+		ranges: merged,
+		values: values,
+	}, nil
+}
+
+// rangeTable 将某个值的全部字符点压缩为 unicode.RangeTable 形式的 Range16/Range32
+func (p *property) rangeTable(value string) ([]unicode.Range16, []unicode.Range32) {
+	var runes []rune
+	for _, r := range p.ranges {
+		if r.value != value {
+			continue
+		}
+		for c := r.lo; c <= r.hi; c++ {
+			runes = append(runes, c)
+		}
+	}
+
+	split := sort.Search(len(runes), func(i int) bool { return runes[i] > 0xFFFF })
+
+	var r16 []unicode.Range16
+	for _, r := range compress(runes[:split]) {
+		r16 = append(r16, unicode.Range16{Lo: uint16(r[0]), Hi: uint16(r[1]), Stride: uint16(r[2])})
+	}
+
+	var r32 []unicode.Range32
+	for _, r := range compress(runes[split:]) {
+		r32 = append(r32, unicode.Range32{Lo: uint32(r[0]), Hi: uint32(r[1]), Stride: uint32(r[2])})
+	}
+
+	return r16, r32
+}
+
+// compress 将有序字符点按固定步长贪心合并为 {lo, hi, stride}
+func compress(runes []rune) [][3]rune {
+	var ranges [][3]rune
+	for i := 0; i < len(runes); {
+		lo, hi, stride := runes[i], runes[i], rune(1)
+		j := i + 1
+		if j < len(runes) {
+			stride = runes[j] - lo
+			hi = runes[j]
+			j++
+			for j < len(runes) && runes[j]-hi == stride {
+				hi = runes[j]
+				j++
+			}
+		}
+		ranges = append(ranges, [3]rune{lo, hi, stride})
+		i = j
+	}
+	return ranges
+}
+
+// latinOffset Range16 中 Hi <= MaxLatin1 的条目数
+func latinOffset(r16 []unicode.Range16) int {
+	n := 0
+	for _, r := range r16 {
+		if r.Hi <= unicode.MaxLatin1 {
+			n++
+		}
+	}
+	return n
+}
+
+// generateSource 生成查找表源代码
+func generateSource(properties []*property, opts Options) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by udc2mongo generate; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", opts.Package)
+	fmt.Fprintf(&b, "import (\n\t\"sort\"\n\t\"unicode\"\n)\n\n")
+	fmt.Fprintf(&b, "// UnicodeVersion is the Unicode version the tables were generated from.\n")
+	fmt.Fprintf(&b, "const UnicodeVersion = %q\n\n", opts.Version)
+	fmt.Fprintf(&b, "type valueRange struct {\n\tLo, Hi uint32\n\tValue uint16\n}\n\n")
+
+	for _, p := range properties {
+		valueIndex := make(map[string]int, len(p.values))
+		for i, v := range p.values {
+			valueIndex[v] = i
+		}
+
+		fmt.Fprintf(&b, "// %sValues lists every %s value.\n", p.goName, p.name)
+		fmt.Fprintf(&b, "var %sValues = []string{\n", p.goName)
+		for _, v := range p.values {
+			fmt.Fprintf(&b, "\t%q,\n", v)
+		}
+		fmt.Fprintf(&b, "}\n\n")
+
+		fmt.Fprintf(&b, "var %sRanges = []valueRange{\n", lowerFirst(p.goName))
+		for _, r := range p.ranges {
+			fmt.Fprintf(&b, "\t{0x%04X, 0x%04X, %d},\n", r.lo, r.hi, valueIndex[r.value])
+		}
+		fmt.Fprintf(&b, "}\n\n")
+
+		fmt.Fprintf(&b, "// %s returns the %s value of r, or \"\" if r is not a code point.\n", p.goName, p.name)
+		fmt.Fprintf(&b, "func %s(r rune) string {\n", p.goName)
+		fmt.Fprintf(&b, "\tranges := %sRanges\n", lowerFirst(p.goName))
+		fmt.Fprintf(&b, "\ti := sort.Search(len(ranges), func(i int) bool { return ranges[i].Hi >= uint32(r) })\n")
+		fmt.Fprintf(&b, "\tif i == len(ranges) || ranges[i].Lo > uint32(r) {\n\t\treturn \"\"\n\t}\n")
+		fmt.Fprintf(&b, "\treturn %sValues[ranges[i].Value]\n}\n\n", p.goName)
+
+		fmt.Fprintf(&b, "// %sTables maps each %s value to its code points.\n", p.goName, p.name)
+		fmt.Fprintf(&b, "var %sTables = map[string]*unicode.RangeTable{\n", p.goName)
+		for _, v := range p.values {
+			fmt.Fprintf(&b, "\t%q: %s,\n", v, tableName(p, v))
+		}
+		fmt.Fprintf(&b, "}\n\n")
+
+		for _, v := range p.values {
+			r16, r32 := p.rangeTable(v)
+			fmt.Fprintf(&b, "var %s = &unicode.RangeTable{\n", tableName(p, v))
+			if len(r16) > 0 {
+				fmt.Fprintf(&b, "\tR16: []unicode.Range16{\n")
+				for _, r := range r16 {
+					fmt.Fprintf(&b, "\t\t{0x%04x, 0x%04x, %d},\n", r.Lo, r.Hi, r.Stride)
+				}
+				fmt.Fprintf(&b, "\t},\n")
+			}
+			if len(r32) > 0 {
+				fmt.Fprintf(&b, "\tR32: []unicode.Range32{\n")
+				for _, r := range r32 {
+					fmt.Fprintf(&b, "\t\t{0x%x, 0x%x, %d},\n", r.Lo, r.Hi, r.Stride)
+				}
+				fmt.Fprintf(&b, "\t},\n")
+			}
+			if n := latinOffset(r16); n > 0 {
+				fmt.Fprintf(&b, "\tLatinOffset: %d,\n", n)
+			}
+			fmt.Fprintf(&b, "}\n\n")
+		}
+	}
+
+	return b.Bytes()
+}
+
+// generateTest 生成测试，检查每个范围的两端
+func generateTest(properties []*property, opts Options) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by udc2mongo generate; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", opts.Package)
+	fmt.Fprintf(&b, "import (\n\t\"testing\"\n\t\"unicode\"\n)\n\n")
+
+	for _, p := range properties {
+		fmt.Fprintf(&b, "func Test%s(t *testing.T) {\n", p.goName)
+		fmt.Fprintf(&b, "\ttests := []struct {\n\t\tr    rune\n\t\twant string\n\t}{\n")
+		for _, r := range p.ranges {
+			fmt.Fprintf(&b, "\t\t{0x%04X, %q},\n", r.lo, r.value)
+			if r.hi != r.lo {
+				fmt.Fprintf(&b, "\t\t{0x%04X, %q},\n", r.hi, r.value)
+			}
+		}
+		fmt.Fprintf(&b, "\t}\n\n")
+		fmt.Fprintf(&b, "\tfor _, tt := range tests {\n")
+		fmt.Fprintf(&b, "\t\tif got := %s(tt.r); got != tt.want {\n", p.goName)
+		fmt.Fprintf(&b, "\t\t\tt.Errorf(\"%s(%%U) = %%q, want %%q\", tt.r, got, tt.want)\n\t\t}\n", p.goName)
+		fmt.Fprintf(&b, "\t\tif !unicode.Is(%sTables[tt.want], tt.r) {\n", p.goName)
+		fmt.Fprintf(&b, "\t\t\tt.Errorf(\"%%U not in %sTables[%%q]\", tt.r, tt.want)\n\t\t}\n", p.goName)
+		fmt.Fprintf(&b, "\t}\n}\n\n")
+	}
+
+	return b.Bytes()
+}
+
+// tableName 值对应的 RangeTable 变量名
+func tableName(p *property, value string) string {
+	return lowerFirst(p.goName) + "_" + goIdentifier(value)
+}
+
+// goIdentifier 将 snake_case 或属性值转换为 Go 标识符
+func goIdentifier(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, c := range s {
+		switch {
+		case c == '_' || c == '-' || c == ' ':
+			upper = true
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			if upper {
+				c = unicode.ToUpper(c)
+				upper = false
+			}
+			b.WriteRune(c)
+		}
+	}
+	if b.Len() == 0 {
+		return "Empty"
+	}
+	return b.String()
+}
+
+// lowerFirst 首字母小写
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
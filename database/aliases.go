@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"udc2mongo/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SavePropertyAliases 替换 property_aliases 中的属性值别名
+func (mc *MongoClient) SavePropertyAliases(ctx context.Context, aliases []model.PropertyAlias) (err error) {
+	defer mc.observe("SavePropertyAliases", time.Now(), &err)
+
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Bulk)
+	defer cancel()
+
+	mc.logger.Debug("clearing existing property aliases", "collection", mc.aliases.Name())
+	_, err = mc.aliases.DeleteMany(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("failed to clear existing property aliases: %w", err)
+	}
+
+	now := time.Now()
+	documents := make([]interface{}, len(aliases))
+	for i := range aliases {
+		aliases[i].ID = primitive.NewObjectID()
+		aliases[i].CreatedAt = now
+		aliases[i].UpdatedAt = now
+		documents[i] = aliases[i]
+	}
+
+	elapsed, err := mc.bulkInsert(ctx, mc.aliases, documents)
+	if err != nil {
+		return err
+	}
+
+	_, err = mc.aliases.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "property", Value: 1},
+			{Key: "short", Value: 1},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create property aliases index: %w", err)
+	}
+
+	mc.logger.Info("saved property aliases", throughput(len(aliases), elapsed)...)
+	return nil
+}
+
+// GetPropertyAliases 读取 property_aliases，集合为空时返回空表，查找时原样返回输入
+func (mc *MongoClient) GetPropertyAliases(ctx context.Context) (_ *model.PropertyAliases, err error) {
+	defer mc.observe("GetPropertyAliases", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	cursor, err := mc.aliases.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find property aliases: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var aliases []model.PropertyAlias
+	err = cursor.All(ctx, &aliases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode property aliases: %w", err)
+	}
+
+	return model.NewPropertyAliases(aliases), nil
+}
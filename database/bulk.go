@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+	"udc2mongo/progress"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BulkOptions 批量写入选项
+type BulkOptions struct {
+	BatchSize   int // 每批文档数
+	Concurrency int // 同时写入的批数
+	MaxRetries  int // 每批在临时错误后的最大重试次数
+}
+
+// DefaultBulkOptions 默认批量写入选项
+var DefaultBulkOptions = BulkOptions{
+	BatchSize:   1000,
+	Concurrency: 4,
+	MaxRetries:  3,
+}
+
+// ParseBulkOptions 解析批大小、并发数和重试次数，空字符串使用默认值
+func ParseBulkOptions(batchSize, concurrency, maxRetries string) (BulkOptions, error) {
+	opts := DefaultBulkOptions
+	for _, o := range []struct {
+		name  string
+		value string
+		min   int
+		dst   *int
+	}{
+		{"batch size", batchSize, 1, &opts.BatchSize},
+		{"write concurrency", concurrency, 1, &opts.Concurrency},
+		{"write retries", maxRetries, 0, &opts.MaxRetries},
+	} {
+		if o.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(o.value)
+		if err != nil || n < o.min {
+			return opts, fmt.Errorf("invalid %s %q", o.name, o.value)
+		}
+		*o.dst = n
+	}
+	return opts, nil
+}
+
+// retryBackoff 第一次重试前的等待时间，之后每次加倍
+var retryBackoff = 100 * time.Millisecond
+
+// SetBulkOptions 设置 SaveCodePoints、SaveBlocks 和 SaveChanges 使用的批量写入选项
+func (mc *MongoClient) SetBulkOptions(opts BulkOptions) {
+	mc.bulk = opts
+}
+
+// bulkWriter bulkInsert 使用的集合方法，*mongo.Collection 实现了这个接口
+type bulkWriter interface {
+	Name() string
+	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+}
+
+// bulkInsert 将文档分批并发写入，批内无序，返回写入耗时
+//
+// 文档的 _id 在写入前已生成，重试整批时已写入的文档会报重复键错误，可以安全忽略。
+func (mc *MongoClient) bulkInsert(ctx context.Context, collection bulkWriter, documents []interface{}) (time.Duration, error) {
+	start := time.Now()
+	tracker := mc.progress.Start(progress.Stage{Name: "write " + collection.Name(), Unit: progress.Documents, Total: int64(len(documents))})
+	defer tracker.Done()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan [2]int)
+	go func() {
+		defer close(batches)
+		for i := 0; i < len(documents); i += mc.bulk.BatchSize {
+			end := min(i+mc.bulk.BatchSize, len(documents))
+			select {
+			case batches <- [2]int{i, end}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for w := 0; w < mc.bulk.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				err := mc.insertBatch(ctx, collection, documents[b[0]:b[1]])
+				if err != nil {
+					once.Do(func() {
+						firstErr = fmt.Errorf("failed to insert %s batch %d-%d: %w", collection.Name(), b[0], b[1], err)
+						cancel()
+					})
+					return
+				}
+				mc.logger.Debug("inserted batch", "collection", collection.Name(), "from", b[0], "to", b[1])
+				tracker.Add(int64(b[1] - b[0]))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// insertBatch 用无序 BulkWrite 写入一批，临时错误按指数退避重试
+func (mc *MongoClient) insertBatch(ctx context.Context, collection bulkWriter, documents []interface{}) error {
+	models := make([]mongo.WriteModel, len(documents))
+	for i, doc := range documents {
+		models[i] = mongo.NewInsertOneModel().SetDocument(doc)
+	}
+
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if err == nil || (attempt > 0 && onlyDuplicateKeys(err)) {
+			return nil
+		}
+		if attempt >= mc.bulk.MaxRetries || !isTransient(err) {
+			return err
+		}
+
+		mc.logger.Warn("retrying batch", "collection", collection.Name(), "attempt", attempt+1, "error", err)
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// isTransient 检查错误是否可以重试
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
+	}
+
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) {
+		return labeled.HasErrorLabel("RetryableWriteError") || labeled.HasErrorLabel("TransientTransactionError")
+	}
+	return false
+}
+
+// onlyDuplicateKeys 检查批量写入是否只因重复键失败，即上次尝试已写入这些文档
+func onlyDuplicateKeys(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 {
+			return false
+		}
+	}
+	return true
+}
+
+// throughput 写入数量、耗时和速度的日志属性
+func throughput(count int, elapsed time.Duration) []any {
+	attrs := []any{"count", count, "elapsed", elapsed.Round(time.Millisecond)}
+	if elapsed > 0 {
+		attrs = append(attrs, "docs_per_sec", int64(float64(count)/elapsed.Seconds()))
+	}
+	return attrs
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"udc2mongo/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartImportRun 记录导入开始
+func (mc *MongoClient) StartImportRun(ctx context.Context, run *model.ImportRun) (err error) {
+	defer mc.observe("StartImportRun", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	run.ID = primitive.NewObjectID()
+	run.Status = model.ImportStarted
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+
+	_, err = mc.importRuns.InsertOne(ctx, run)
+	if err != nil {
+		return fmt.Errorf("failed to record import run: %w", err)
+	}
+	return nil
+}
+
+// FinishImportRun 记录导入结果，err 为 nil 表示已提交
+func (mc *MongoClient) FinishImportRun(ctx context.Context, run *model.ImportRun, runErr error) (err error) {
+	defer mc.observe("FinishImportRun", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	run.Status = model.ImportCommitted
+	if runErr != nil {
+		run.Status = model.ImportFailed
+		run.Error = runErr.Error()
+	}
+	run.FinishedAt = time.Now()
+
+	_, err = mc.importRuns.ReplaceOne(ctx, bson.M{"_id": run.ID}, run)
+	if err != nil {
+		return fmt.Errorf("failed to update import run: %w", err)
+	}
+	return nil
+}
+
+// ListImportRuns 按开始时间倒序列出最近的导入
+func (mc *MongoClient) ListImportRuns(ctx context.Context, limit int64) (_ []model.ImportRun, err error) {
+	defer mc.observe("ListImportRuns", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	cursor, err := mc.importRuns.Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: -1}}).
+		SetLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find import runs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var runs []model.ImportRun
+	err = cursor.All(ctx, &runs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode import runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetImportRun 按 ID 查找导入记录，不存在时返回 nil
+func (mc *MongoClient) GetImportRun(ctx context.Context, id string) (_ *model.ImportRun, err error) {
+	defer mc.observe("GetImportRun", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid import run ID %q", id)
+	}
+
+	var run model.ImportRun
+	err = mc.importRuns.FindOne(ctx, bson.M{"_id": objectID}).Decode(&run)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find import run %s: %w", id, err)
+	}
+
+	return &run, nil
+}
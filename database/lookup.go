@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"udc2mongo/model"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CharacterInfo 字符串中单个字符的属性
+type CharacterInfo struct {
+	Offset int    `json:"offset"` // 在字符串中的字节偏移
+	CP     string `json:"cp"`
+	Char   string `json:"char"`
+	Found  bool   `json:"found"` // 数据库中是否有该字符点的条目
+
+	Name                string `json:"name,omitempty"` // 范围条目名称中的 # 已替换为字符点
+	GeneralCategory     string `json:"general_category,omitempty"`
+	GeneralCategoryName string `json:"general_category_name,omitempty"`
+	Script              string `json:"script,omitempty"`
+	ScriptName          string `json:"script_name,omitempty"`
+	BidiClass           string `json:"bidi_class,omitempty"`
+	BidiClassName       string `json:"bidi_class_name,omitempty"`
+	EastAsianWidth      string `json:"east_asian_width,omitempty"`
+	EastAsianWidthName  string `json:"east_asian_width_name,omitempty"`
+
+	Emoji                bool `json:"emoji"`
+	EmojiPresentation    bool `json:"emoji_presentation"`
+	EmojiModifier        bool `json:"emoji_modifier"`
+	EmojiModifierBase    bool `json:"emoji_modifier_base"`
+	EmojiComponent       bool `json:"emoji_component"`
+	ExtendedPictographic bool `json:"extended_pictographic"`
+
+	// 需要 QA 注意的字符
+	InvalidUTF8      bool `json:"invalid_utf8"`      // 不是合法的 UTF-8，CP 为 FFFD
+	Invisible        bool `json:"invisible"`         // 没有可见字形：Cc、Cf、Zs、Zl、Zp、Cn 或默认可忽略
+	DefaultIgnorable bool `json:"default_ignorable"` // Default_Ignorable_Code_Point
+	BidiControl      bool `json:"bidi_control"`      // Bidi_Control，例如 U+202E
+}
+
+// invisibleCategories 没有可见字形的 gc
+var invisibleCategories = map[string]bool{
+	"Cc": true, "Cf": true, "Zs": true, "Zl": true, "Zp": true, "Cn": true,
+}
+
+// LookupString 按顺序返回字符串中每个字符的属性
+//
+// 单个字符点一次查询取出，其余字符再用一次查询匹配范围条目。
+func (mc *MongoClient) LookupString(ctx context.Context, s string) (_ []CharacterInfo, err error) {
+	defer mc.observe("LookupString", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	infos := make([]CharacterInfo, 0, utf8.RuneCountInString(s))
+	wanted := make(map[rune]bool)
+	for offset, r := range s {
+		info := CharacterInfo{Offset: offset, CP: model.FormatCodePoint(r), Char: string(r)}
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[offset:]); size == 1 {
+				info.InvalidUTF8 = true
+			}
+		}
+		infos = append(infos, info)
+		wanted[r] = true
+	}
+	if len(infos) == 0 {
+		return infos, nil
+	}
+
+	found := make(map[rune]*model.CodePoint, len(wanted))
+	hexes := make([]string, 0, len(wanted))
+	for r := range wanted {
+		hexes = append(hexes, model.FormatCodePoint(r))
+	}
+	codePoints, err := mc.findCodePoints(ctx, bson.M{"cp": bson.M{"$in": hexes}})
+	if err != nil {
+		return nil, err
+	}
+	for i := range codePoints {
+		if r, err := model.ParseCodePoint(codePoints[i].CP); err == nil {
+			found[r] = &codePoints[i]
+		}
+	}
+
+	// 剩下的字符在范围条目中，例如 CJK 统一表意文字和保留字符点
+	var missing bson.A
+	for r := range wanted {
+		if found[r] == nil {
+			missing = append(missing, rangeFilter(r, r))
+		}
+	}
+	if len(missing) > 0 {
+		ranges, err := mc.findCodePoints(ctx, bson.M{"cp": "", "$or": missing})
+		if err != nil {
+			return nil, err
+		}
+		for i := range ranges {
+			first, err1 := model.ParseCodePoint(ranges[i].FirstCP)
+			last, err2 := model.ParseCodePoint(ranges[i].LastCP)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			for r := range wanted {
+				if found[r] == nil && first <= r && r <= last {
+					found[r] = &ranges[i]
+				}
+			}
+		}
+	}
+
+	for i := range infos {
+		r, _ := utf8.DecodeRuneInString(infos[i].Char)
+		if cp := found[r]; cp != nil {
+			infos[i].fill(cp)
+		}
+	}
+	return infos, nil
+}
+
+// fill 从字符点条目复制属性并计算标记
+func (info *CharacterInfo) fill(cp *model.CodePoint) {
+	info.Found = true
+	info.Name = strings.ReplaceAll(cp.Name, "#", info.CP)
+	info.GeneralCategory = cp.GeneralCategory
+	info.GeneralCategoryName = cp.GeneralCategoryName
+	info.Script = cp.Script
+	info.ScriptName = cp.ScriptName
+	info.BidiClass = cp.BidiClass
+	info.BidiClassName = cp.BidiClassName
+	info.EastAsianWidth = cp.EastAsianWidth
+	info.EastAsianWidthName = cp.EastAsianWidthName
+
+	info.Emoji = bool(cp.Emoji)
+	info.EmojiPresentation = bool(cp.EmojiPresentation)
+	info.EmojiModifier = bool(cp.EmojiModifier)
+	info.EmojiModifierBase = bool(cp.EmojiModifierBase)
+	info.EmojiComponent = bool(cp.EmojiComponent)
+	info.ExtendedPictographic = bool(cp.ExtendedPictographic)
+
+	info.DefaultIgnorable = bool(cp.DefaultIgnorable)
+	info.BidiControl = bool(cp.BidiControl)
+	info.Invisible = info.DefaultIgnorable || invisibleCategories[cp.GeneralCategory]
+}
+
+// findCodePoints 按条件查询全部匹配的字符点
+func (mc *MongoClient) findCodePoints(ctx context.Context, filter bson.M) ([]model.CodePoint, error) {
+	cursor, err := mc.CodePoints.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find code points: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var codePoints []model.CodePoint
+	err = cursor.All(ctx, &codePoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode code points: %w", err)
+	}
+	return codePoints, nil
+}
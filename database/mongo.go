@@ -3,8 +3,10 @@ package database
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 	"udc2mongo/model"
+	"udc2mongo/progress"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -18,10 +20,18 @@ type MongoClient struct {
 	ucd        *mongo.Collection
 	CodePoints *mongo.Collection
 	blocks     *mongo.Collection
+	changes    *mongo.Collection
+	importRuns *mongo.Collection
+	aliases    *mongo.Collection
+	timeouts   Timeouts
+	bulk       BulkOptions
+	logger     *slog.Logger
+	progress   progress.Reporter
+	observer   CallObserver
 }
 
-func NewMongoClient(uri, dbName string) (*MongoClient, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func NewMongoClient(ctx context.Context, uri, dbName string, timeouts Timeouts, opts ClientOptions) (*MongoClient, error) {
+	ctx, cancel := withTimeout(ctx, timeouts.Connect)
 	defer cancel()
 
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
@@ -43,53 +53,67 @@ func NewMongoClient(uri, dbName string) (*MongoClient, error) {
 		ucd:        database.Collection("ucd"),
 		CodePoints: database.Collection("code_points"),
 		blocks:     database.Collection("blocks"),
+		changes:    database.Collection("ucd_changes"),
+		importRuns: database.Collection("import_runs"),
+		aliases:    database.Collection("property_aliases"),
+		timeouts:   timeouts,
+		bulk:       DefaultBulkOptions,
+		logger:     opts.logger(),
+		progress:   opts.progress(),
+		observer:   opts.Observer,
 	}, nil
 }
 
+// Close 断开连接，不使用调用方的 ctx，保证中断后仍能正常断开
 func (mc *MongoClient) Close() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := withTimeout(context.Background(), mc.timeouts.Connect)
 	defer cancel()
 	return mc.client.Disconnect(ctx)
 }
 
-func (mc *MongoClient) SaveUCD(ucd *model.UCD) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func (mc *MongoClient) SaveUCD(ctx context.Context, ucd *model.UCD) (err error) {
+	defer mc.observe("SaveUCD", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Bulk)
 	defer cancel()
 
 	ucdMetadata := &model.UCD{
 		Xmlns:       ucd.Xmlns,
 		Description: ucd.Description,
 		Version:     ucd.Version,
+		Variant:     ucd.Variant,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	fmt.Println("Clearing existing UCD metadata...")
-	err := mc.ucd.Drop(ctx)
+	// 不删除集合，保留 CreateValidators 安装的校验
+	mc.logger.Debug("clearing existing UCD metadata", "collection", mc.ucd.Name())
+	_, err = mc.ucd.DeleteMany(ctx, bson.M{})
 	if err != nil {
 		return fmt.Errorf("failed to clear existing UCD data: %w", err)
 	}
 
-	fmt.Println("Saving UCD metadata...")
 	result, err := mc.ucd.InsertOne(ctx, ucdMetadata)
 	if err != nil {
 		return fmt.Errorf("failed to save UCD: %w", err)
 	}
 
-	fmt.Printf("UCD metadata saved with ID: %v\n", result.InsertedID)
+	mc.logger.Info("saved UCD metadata", "id", result.InsertedID, "version", ucd.Version)
 	return nil
 }
 
-func (mc *MongoClient) SaveCodePoints(codePoints []model.CodePoint) error {
+func (mc *MongoClient) SaveCodePoints(ctx context.Context, codePoints []model.CodePoint) (err error) {
+	defer mc.observe("SaveCodePoints", time.Now(), &err)
+
 	if len(codePoints) == 0 {
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Bulk)
 	defer cancel()
 
-	fmt.Println("Clearing existing code points...")
-	_, err := mc.CodePoints.DeleteMany(ctx, bson.M{})
+	mc.logger.Debug("clearing existing code points", "collection", mc.CodePoints.Name())
+	_, err = mc.CodePoints.DeleteMany(ctx, bson.M{})
 	if err != nil {
 		return fmt.Errorf("failed to clear existing code points: %w", err)
 	}
@@ -103,38 +127,30 @@ func (mc *MongoClient) SaveCodePoints(codePoints []model.CodePoint) error {
 		documents[i] = codePoints[i]
 	}
 
-	fmt.Printf("Inserting %d code points...\n", len(documents))
-
-	batchSize := 1000
-	for i := 0; i < len(documents); i += batchSize {
-		end := i + batchSize
-		if end > len(documents) {
-			end = len(documents)
-		}
-
-		batch := documents[i:end]
-		_, err := mc.CodePoints.InsertMany(ctx, batch)
-		if err != nil {
-			return fmt.Errorf("failed to insert code points batch %d-%d: %w", i, end, err)
-		}
+	mc.logger.Info("inserting code points", "count", len(documents),
+		"batch_size", mc.bulk.BatchSize, "concurrency", mc.bulk.Concurrency)
 
-		fmt.Printf("Inserted batch %d-%d\n", i, end)
+	elapsed, err := mc.bulkInsert(ctx, mc.CodePoints, documents)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Successfully saved %d code points\n", len(codePoints))
+	mc.logger.Info("saved code points", throughput(len(codePoints), elapsed)...)
 	return nil
 }
 
-func (mc *MongoClient) SaveBlocks(blocks []model.Block) error {
+func (mc *MongoClient) SaveBlocks(ctx context.Context, blocks []model.Block) (err error) {
+	defer mc.observe("SaveBlocks", time.Now(), &err)
+
 	if len(blocks) == 0 {
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Bulk)
 	defer cancel()
 
-	fmt.Println("Clearing existing blocks...")
-	_, err := mc.blocks.DeleteMany(ctx, bson.M{})
+	mc.logger.Debug("clearing existing blocks", "collection", mc.blocks.Name())
+	_, err = mc.blocks.DeleteMany(ctx, bson.M{})
 	if err != nil {
 		return fmt.Errorf("failed to clear existing blocks: %w", err)
 	}
@@ -148,29 +164,56 @@ func (mc *MongoClient) SaveBlocks(blocks []model.Block) error {
 		documents[i] = blocks[i]
 	}
 
-	fmt.Printf("Inserting %d blocks...\n", len(documents))
-	_, err = mc.blocks.InsertMany(ctx, documents)
+	elapsed, err := mc.bulkInsert(ctx, mc.blocks, documents)
 	if err != nil {
-		return fmt.Errorf("failed to insert blocks: %w", err)
+		return err
 	}
 
-	fmt.Printf("Successfully saved %d blocks\n", len(blocks))
+	mc.logger.Info("saved blocks", throughput(len(blocks), elapsed)...)
 	return nil
 }
 
-func (mc *MongoClient) CreateIndexes() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// Clear 删除指定集合（ucd、code_points 或 blocks）中的全部数据，用于清理中断的导入
+func (mc *MongoClient) Clear(ctx context.Context, collections ...string) (err error) {
+	defer mc.observe("Clear", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Bulk)
 	defer cancel()
 
-	fmt.Println("Creating indexes...")
+	for _, name := range collections {
+		var collection *mongo.Collection
+		switch name {
+		case "ucd":
+			collection = mc.ucd
+		case "code_points":
+			collection = mc.CodePoints
+		case "blocks":
+			collection = mc.blocks
+		default:
+			return fmt.Errorf("unknown collection %q", name)
+		}
+
+		_, err := collection.DeleteMany(ctx, bson.M{})
+		if err != nil {
+			return fmt.Errorf("failed to clear %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (mc *MongoClient) CreateIndexes(ctx context.Context) (err error) {
+	defer mc.observe("CreateIndexes", time.Now(), &err)
 
-	fmt.Println("Dropping existing indexes...")
-	_, err := mc.CodePoints.Indexes().DropAll(ctx)
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	mc.logger.Info("creating indexes", "collections", []string{mc.CodePoints.Name(), mc.blocks.Name()})
+	_, err = mc.CodePoints.Indexes().DropAll(ctx)
 	if err != nil {
 		if !isNamespaceNotFoundError(err) {
 			return fmt.Errorf("failed to drop existing indexes: %w", err)
 		}
-		fmt.Println("Code points collection doesn't exist yet, skipping index drop")
+		mc.logger.Debug("collection does not exist yet, skipping index drop", "collection", mc.CodePoints.Name())
 	}
 
 	_, err = mc.blocks.Indexes().DropAll(ctx)
@@ -178,7 +221,7 @@ func (mc *MongoClient) CreateIndexes() error {
 		if !isNamespaceNotFoundError(err) {
 			return fmt.Errorf("failed to drop existing block indexes: %w", err)
 		}
-		fmt.Println("Blocks collection doesn't exist yet, skipping index drop")
+		mc.logger.Debug("collection does not exist yet, skipping index drop", "collection", mc.blocks.Name())
 	}
 
 	codePointIndexes := []mongo.IndexModel{
@@ -192,6 +235,9 @@ func (mc *MongoClient) CreateIndexes() error {
 		{
 			Keys: bson.D{{Key: "block", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "block_name", Value: 1}},
+		},
 		{
 			Keys: bson.D{{Key: "general_category", Value: 1}},
 		},
@@ -207,6 +253,23 @@ func (mc *MongoClient) CreateIndexes() error {
 				{Key: "last_cp", Value: 1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "name", Value: "text"},
+				{Key: "name1", Value: "text"},
+				{Key: "name_aliases.alias", Value: "text"},
+				{Key: "k_definition", Value: "text"},
+			},
+			Options: options.Index().
+				SetName(textIndexName).
+				SetDefaultLanguage("none"). // 不做词干提取，也不过滤 "A" 这样的停用词
+				SetWeights(bson.D{
+					{Key: "name", Value: 10},
+					{Key: "name_aliases.alias", Value: 5},
+					{Key: "name1", Value: 3},
+					{Key: "k_definition", Value: 1},
+				}),
+		},
 	}
 
 	_, err = mc.CodePoints.Indexes().CreateMany(ctx, codePointIndexes)
@@ -219,6 +282,9 @@ func (mc *MongoClient) CreateIndexes() error {
 			Keys:    bson.D{{Key: "name", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			Keys: bson.D{{Key: "alias", Value: 1}},
+		},
 		{
 			Keys: bson.D{
 				{Key: "first_cp", Value: 1},
@@ -232,16 +298,18 @@ func (mc *MongoClient) CreateIndexes() error {
 		return fmt.Errorf("failed to create blocks indexes: %w", err)
 	}
 
-	fmt.Println("Indexes created successfully")
+	mc.logger.Info("indexes created")
 	return nil
 }
 
-func (mc *MongoClient) GetCodePointByCP(cp string) (*model.CodePoint, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (mc *MongoClient) GetCodePointByCP(ctx context.Context, cp string) (_ *model.CodePoint, err error) {
+	defer mc.observe("GetCodePointByCP", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
 	defer cancel()
 
 	var codePoint model.CodePoint
-	err := mc.CodePoints.FindOne(ctx, bson.M{"cp": cp}).Decode(&codePoint)
+	err = mc.CodePoints.FindOne(ctx, bson.M{"cp": cp}).Decode(&codePoint)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
@@ -252,11 +320,17 @@ func (mc *MongoClient) GetCodePointByCP(cp string) (*model.CodePoint, error) {
 	return &codePoint, nil
 }
 
-func (mc *MongoClient) GetCodePointsByBlock(blockName string) ([]model.CodePoint, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// GetCodePointsByBlock 按块查询字符点，blockName 可以是 blk 别名（ASCII）或全名（Basic Latin）
+func (mc *MongoClient) GetCodePointsByBlock(ctx context.Context, blockName string) (_ []model.CodePoint, err error) {
+	defer mc.observe("GetCodePointsByBlock", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
 	defer cancel()
 
-	cursor, err := mc.CodePoints.Find(ctx, bson.M{"block": blockName})
+	cursor, err := mc.CodePoints.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"block": blockName},
+		bson.M{"block_name": blockName},
+	}})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find code points in block %s: %w", blockName, err)
 	}
@@ -271,8 +345,121 @@ func (mc *MongoClient) GetCodePointsByBlock(blockName string) ([]model.CodePoint
 	return codePoints, nil
 }
 
-func (mc *MongoClient) GetStats() (*DatabaseStats, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (mc *MongoClient) GetUCD(ctx context.Context) (_ *model.UCD, err error) {
+	defer mc.observe("GetUCD", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	var ucd model.UCD
+	err = mc.ucd.FindOne(ctx, bson.M{}).Decode(&ucd)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find UCD metadata: %w", err)
+	}
+
+	return &ucd, nil
+}
+
+func (mc *MongoClient) GetAllCodePoints(ctx context.Context) (_ []model.CodePoint, err error) {
+	defer mc.observe("GetAllCodePoints", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Bulk)
+	defer cancel()
+
+	cursor, err := mc.CodePoints.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find code points: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var codePoints []model.CodePoint
+	err = cursor.All(ctx, &codePoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode code points: %w", err)
+	}
+
+	return codePoints, nil
+}
+
+func (mc *MongoClient) GetAllBlocks(ctx context.Context) (_ []model.Block, err error) {
+	defer mc.observe("GetAllBlocks", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Bulk)
+	defer cancel()
+
+	cursor, err := mc.blocks.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blocks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var blocks []model.Block
+	err = cursor.All(ctx, &blocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode blocks: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// SaveChanges 保存变更集，替换同一版本对之前的记录
+func (mc *MongoClient) SaveChanges(ctx context.Context, changeSet *model.ChangeSet) (err error) {
+	defer mc.observe("SaveChanges", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Bulk)
+	defer cancel()
+
+	filter := bson.M{
+		"from_version": changeSet.FromVersion,
+		"to_version":   changeSet.ToVersion,
+	}
+
+	mc.logger.Debug("clearing existing changes", "from", changeSet.FromVersion, "to", changeSet.ToVersion)
+	_, err = mc.changes.DeleteMany(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to clear existing changes: %w", err)
+	}
+
+	if len(changeSet.Changes) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	documents := make([]interface{}, len(changeSet.Changes))
+	for i := range changeSet.Changes {
+		changeSet.Changes[i].ID = primitive.NewObjectID()
+		changeSet.Changes[i].CreatedAt = now
+		documents[i] = changeSet.Changes[i]
+	}
+
+	elapsed, err := mc.bulkInsert(ctx, mc.changes, documents)
+	if err != nil {
+		return err
+	}
+
+	_, err = mc.changes.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "from_version", Value: 1},
+			{Key: "to_version", Value: 1},
+			{Key: "key", Value: 1},
+			{Key: "property", Value: 1},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create changes index: %w", err)
+	}
+
+	mc.logger.Info("saved changes", throughput(len(changeSet.Changes), elapsed)...)
+	return nil
+}
+
+func (mc *MongoClient) GetStats(ctx context.Context) (_ *DatabaseStats, err error) {
+	defer mc.observe("GetStats", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
 	defer cancel()
 
 	stats := &DatabaseStats{}
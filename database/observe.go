@@ -0,0 +1,13 @@
+package database
+
+import "time"
+
+// CallObserver 接收 MongoClient 方法的名称、耗时和返回的错误
+type CallObserver func(method string, elapsed time.Duration, err error)
+
+// observe 在方法返回时报告，用法为 defer mc.observe("GetStats", time.Now(), &err)
+func (mc *MongoClient) observe(method string, start time.Time, err *error) {
+	if mc.observer != nil {
+		mc.observer(method, time.Since(start), *err)
+	}
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"log/slog"
+	"udc2mongo/progress"
+)
+
+// ClientOptions 客户端的可选依赖，零值可以直接使用
+type ClientOptions struct {
+	// Logger 客户端日志，nil 表示丢弃，嵌入其他程序时不会输出到 stdout
+	Logger *slog.Logger
+	// Progress 批量写入的进度报告，nil 表示不报告
+	Progress progress.Reporter
+	// Observer 接收 MongoClient 每次方法调用的耗时和错误，nil 表示不记录
+	Observer CallObserver
+}
+
+// logger 返回配置的日志，未配置时丢弃
+func (o ClientOptions) logger() *slog.Logger {
+	if o.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return o.Logger
+}
+
+// progress 返回配置的进度报告，未配置时不报告
+func (o ClientOptions) progress() progress.Reporter {
+	if o.Progress == nil {
+		return progress.Discard
+	}
+	return o.Progress
+}
@@ -0,0 +1,489 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"udc2mongo/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// majorCategories gc 大类，例如 L 或 L* 表示所有字母
+var majorCategories = map[string][]string{
+	"L":  {"Lu", "Ll", "Lt", "Lm", "Lo"},
+	"LC": {"Lu", "Ll", "Lt"},
+	"M":  {"Mn", "Mc", "Me"},
+	"N":  {"Nd", "Nl", "No"},
+	"P":  {"Pc", "Pd", "Ps", "Pe", "Pi", "Pf", "Po"},
+	"S":  {"Sm", "Sc", "Sk", "So"},
+	"Z":  {"Zs", "Zl", "Zp"},
+	"C":  {"Cc", "Cf", "Cs", "Co", "Cn"},
+}
+
+// boolProperties code_points 中所有布尔属性的 bson 字段名
+var boolProperties = collectBoolProperties(reflect.TypeOf(model.CodePoint{}))
+
+// collectBoolProperties 递归展开 inline 结构体
+func collectBoolProperties(t reflect.Type) map[string]bool {
+	properties := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for name := range collectBoolProperties(field.Type) {
+				properties[name] = true
+			}
+			continue
+		}
+		if field.Type.Kind() == reflect.Bool {
+			name, _, _ := strings.Cut(field.Tag.Get("bson"), ",")
+			properties[name] = true
+		}
+	}
+	return properties
+}
+
+// CodePointQuery 字符点查询构建器，条件之间为“与”
+//
+// 构建过程中的错误会被保存，在执行查询时返回。
+type CodePointQuery struct {
+	mc         *MongoClient
+	conditions []bson.M
+	values     []valueCondition
+	ageMin     string
+	ageMax     string
+	sortField  string
+	sortOrder  int
+	projection []string
+	limit      int64
+	err        error
+}
+
+// valueCondition 取值需要按 property_aliases 转换为短名的条件
+type valueCondition struct {
+	property string // 属性短名，例如 gc
+	values   []string
+	build    func(values []string) bson.M
+}
+
+// CodePointPage 一页查询结果，Next 为空表示没有更多结果
+type CodePointPage struct {
+	CodePoints []model.CodePoint `json:"code_points"`
+	Next       string            `json:"next,omitempty"`
+}
+
+// Query 创建字符点查询，默认按 _id（即导入顺序）升序
+func (mc *MongoClient) Query() *CodePointQuery {
+	return &CodePointQuery{mc: mc, sortField: "_id", sortOrder: 1}
+}
+
+// Script 按 sc 过滤，多个值为“或”，短名（Hani）和全名（Han）都可以
+func (q *CodePointQuery) Script(scripts ...string) *CodePointQuery {
+	return q.whereValues("sc", "script", scripts)
+}
+
+// ScriptExtensions 按 scx 过滤，scx 以空格分隔，包含任意一个值即匹配
+func (q *CodePointQuery) ScriptExtensions(scripts ...string) *CodePointQuery {
+	q.values = append(q.values, valueCondition{property: "sc", values: scripts, build: func(values []string) bson.M {
+		quoted := make([]string, len(values))
+		for i, s := range values {
+			quoted[i] = regexp.QuoteMeta(s)
+		}
+		pattern := "(^| )(" + strings.Join(quoted, "|") + ")( |$)"
+		return bson.M{"script_extensions": primitive.Regex{Pattern: pattern}}
+	}})
+	return q
+}
+
+// GeneralCategory 按 gc 过滤，支持 L、L*、LC 等大类，以及 Letter、Uppercase_Letter 等全名
+func (q *CodePointQuery) GeneralCategory(categories ...string) *CodePointQuery {
+	trimmed := make([]string, len(categories))
+	for i, c := range categories {
+		trimmed[i] = strings.TrimSuffix(c, "*")
+	}
+	q.values = append(q.values, valueCondition{property: "gc", values: trimmed, build: func(categories []string) bson.M {
+		var values []string
+		for _, c := range categories {
+			if major, ok := majorCategories[c]; ok {
+				values = append(values, major...)
+				continue
+			}
+			values = append(values, c)
+		}
+		return bson.M{"general_category": bson.M{"$in": values}}
+	}})
+	return q
+}
+
+// BidiClass 按 bc 过滤，短名（AL）和全名（Arabic_Letter）都可以
+func (q *CodePointQuery) BidiClass(classes ...string) *CodePointQuery {
+	return q.whereValues("bc", "bidi_class", classes)
+}
+
+// EastAsianWidth 按 ea 过滤，短名（W）和全名（Wide）都可以
+func (q *CodePointQuery) EastAsianWidth(widths ...string) *CodePointQuery {
+	return q.whereValues("ea", "east_asian_width", widths)
+}
+
+// LineBreak 按 lb 过滤，短名（ID）和全名（Ideographic）都可以
+func (q *CodePointQuery) LineBreak(classes ...string) *CodePointQuery {
+	return q.whereValues("lb", "line_break", classes)
+}
+
+// Age 按 age 过滤，min 和 max 为闭区间，空字符串表示不限
+func (q *CodePointQuery) Age(min, max string) *CodePointQuery {
+	for _, v := range []string{min, max} {
+		if _, err := parseAge(v); v != "" && err != nil {
+			q.setErr(err)
+		}
+	}
+	q.ageMin, q.ageMax = min, max
+	return q
+}
+
+// Property 按布尔属性过滤，例如 Property("emoji", true)
+func (q *CodePointQuery) Property(name string, value bool) *CodePointQuery {
+	if !boolProperties[name] {
+		q.setErr(fmt.Errorf("unknown boolean property %q", name))
+		return q
+	}
+	return q.where(bson.M{name: value})
+}
+
+// Block 按块过滤，blk 别名和块全名都可以
+func (q *CodePointQuery) Block(blocks ...string) *CodePointQuery {
+	return q.where(bson.M{"$or": bson.A{
+		bson.M{"block": bson.M{"$in": blocks}},
+		bson.M{"block_name": bson.M{"$in": blocks}},
+	}})
+}
+
+// HasName 只保留有名称的字符点
+func (q *CodePointQuery) HasName() *CodePointQuery {
+	return q.where(bson.M{"name": bson.M{"$exists": true, "$ne": ""}})
+}
+
+// SingleCodePoints 只保留单个字符点，排除 first-cp/last-cp 范围条目
+func (q *CodePointQuery) SingleCodePoints() *CodePointQuery {
+	return q.where(bson.M{"cp": bson.M{"$exists": true, "$ne": ""}})
+}
+
+// CodePointRange 按字符点范围过滤，范围条目与区间有交集即匹配
+func (q *CodePointQuery) CodePointRange(first, last rune) *CodePointQuery {
+	return q.where(rangeFilter(first, last))
+}
+
+// SortBy 按字段排序，_id 作为第二排序键以保证分页稳定
+func (q *CodePointQuery) SortBy(field string, ascending bool) *CodePointQuery {
+	q.sortField, q.sortOrder = field, 1
+	if !ascending {
+		q.sortOrder = -1
+	}
+	return q
+}
+
+// Select 只返回指定字段，_id 和排序字段总是返回
+func (q *CodePointQuery) Select(fields ...string) *CodePointQuery {
+	q.projection = fields
+	return q
+}
+
+// Limit 每页的最大数量，0 表示不限
+func (q *CodePointQuery) Limit(limit int64) *CodePointQuery {
+	q.limit = limit
+	return q
+}
+
+// All 返回所有匹配的字符点
+func (q *CodePointQuery) All(ctx context.Context) (_ []model.CodePoint, err error) {
+	defer q.mc.observe("Query.All", time.Now(), &err)
+
+	page, err := q.page(ctx, "", false)
+	if err != nil {
+		return nil, err
+	}
+	return page.CodePoints, nil
+}
+
+// Page 返回 after 之后的一页，after 为上一页的 Next，第一页传空字符串
+func (q *CodePointQuery) Page(ctx context.Context, after string) (_ *CodePointPage, err error) {
+	defer q.mc.observe("Query.Page", time.Now(), &err)
+
+	return q.page(ctx, after, true)
+}
+
+// Count 返回匹配的数量
+func (q *CodePointQuery) Count(ctx context.Context) (_ int64, err error) {
+	defer q.mc.observe("Query.Count", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, q.mc.timeouts.Operation)
+	defer cancel()
+
+	filter, err := q.filter(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := q.mc.CodePoints.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count code points: %w", err)
+	}
+	return count, nil
+}
+
+func (q *CodePointQuery) page(ctx context.Context, after string, paginate bool) (*CodePointPage, error) {
+	ctx, cancel := withTimeout(ctx, q.mc.timeouts.Operation)
+	defer cancel()
+
+	filter, err := q.filter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if after != "" {
+		keyset, err := q.keyset(after)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.M{"$and": bson.A{filter, keyset}}
+	}
+
+	opts := options.Find()
+	sortKeys := bson.D{{Key: q.sortField, Value: q.sortOrder}}
+	if q.sortField != "_id" {
+		sortKeys = append(sortKeys, bson.E{Key: "_id", Value: q.sortOrder})
+	}
+	opts.SetSort(sortKeys)
+	if q.limit > 0 {
+		opts.SetLimit(q.limit)
+	}
+	if len(q.projection) > 0 {
+		projection := bson.M{"_id": 1, q.sortField: 1}
+		for _, f := range q.projection {
+			projection[f] = 1
+		}
+		opts.SetProjection(projection)
+	}
+
+	cursor, err := q.mc.CodePoints.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query code points: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	page := &CodePointPage{}
+	var last bson.Raw
+	for cursor.Next(ctx) {
+		var cp model.CodePoint
+		if err := cursor.Decode(&cp); err != nil {
+			return nil, fmt.Errorf("failed to decode code points: %w", err)
+		}
+		page.CodePoints = append(page.CodePoints, cp)
+		last = cursor.Current
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to decode code points: %w", err)
+	}
+
+	if paginate && q.limit > 0 && int64(len(page.CodePoints)) == q.limit {
+		page.Next, err = q.encodeCursor(last)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// where 添加一个条件
+func (q *CodePointQuery) where(condition bson.M) *CodePointQuery {
+	q.conditions = append(q.conditions, condition)
+	return q
+}
+
+// whereValues 添加一个按属性值过滤的条件，执行时将取值转换为短名
+func (q *CodePointQuery) whereValues(property, field string, values []string) *CodePointQuery {
+	q.values = append(q.values, valueCondition{property: property, values: values, build: func(values []string) bson.M {
+		return bson.M{field: bson.M{"$in": values}}
+	}})
+	return q
+}
+
+// setErr 保存第一个错误
+func (q *CodePointQuery) setErr(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// filter 合并所有条件，属性值按 property_aliases 转换为短名，age 区间根据库中实际存在的版本展开
+func (q *CodePointQuery) filter(ctx context.Context) (bson.M, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	conditions := append(bson.A{}, toA(q.conditions)...)
+	if len(q.values) > 0 {
+		aliases, err := q.mc.GetPropertyAliases(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range q.values {
+			values := make([]string, len(c.values))
+			for i, v := range c.values {
+				values[i] = aliases.Short(c.property, v)
+			}
+			conditions = append(conditions, c.build(values))
+		}
+	}
+	if q.ageMin != "" || q.ageMax != "" {
+		ages, err := q.mc.CodePoints.Distinct(ctx, "age", bson.M{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ages: %w", err)
+		}
+
+		var matched []string
+		for _, a := range ages {
+			age, ok := a.(string)
+			if ok && ageInRange(age, q.ageMin, q.ageMax) {
+				matched = append(matched, age)
+			}
+		}
+		conditions = append(conditions, bson.M{"age": bson.M{"$in": matched}})
+	}
+
+	if len(conditions) == 0 {
+		return bson.M{}, nil
+	}
+	return bson.M{"$and": conditions}, nil
+}
+
+// keyset 由分页游标生成“排序键大于上一页最后一条”的条件
+func (q *CodePointQuery) keyset(after string) (bson.M, error) {
+	data, err := base64.RawURLEncoding.DecodeString(after)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page cursor: %w", err)
+	}
+
+	var position struct {
+		Value interface{}        `bson:"v"`
+		ID    primitive.ObjectID `bson:"id"`
+	}
+	if err := bson.Unmarshal(data, &position); err != nil {
+		return nil, fmt.Errorf("invalid page cursor: %w", err)
+	}
+
+	op := "$gt"
+	if q.sortOrder < 0 {
+		op = "$lt"
+	}
+
+	if q.sortField == "_id" {
+		return bson.M{"_id": bson.M{op: position.ID}}, nil
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{q.sortField: bson.M{op: position.Value}},
+		bson.M{q.sortField: position.Value, "_id": bson.M{op: position.ID}},
+	}}, nil
+}
+
+// encodeCursor 记录最后一条的排序值和 _id
+func (q *CodePointQuery) encodeCursor(last bson.Raw) (string, error) {
+	position := bson.M{"id": last.Lookup("_id").ObjectID()}
+	if value, err := last.LookupErr(q.sortField); err == nil {
+		position["v"] = value
+	}
+
+	data, err := bson.Marshal(position)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode page cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// rangeFilter 单个字符点在 first..last 中，或范围条目与之有交集
+func rangeFilter(first, last rune) bson.M {
+	lo, hi := model.FormatCodePoint(first), model.FormatCodePoint(last)
+	return bson.M{"$or": bson.A{
+		bson.M{"$and": bson.A{hexGTE("cp", lo), hexLTE("cp", hi)}},
+		bson.M{"$and": bson.A{hexLTE("first_cp", hi), hexGTE("last_cp", lo)}},
+	}}
+}
+
+// hexGTE 字符点字段按数值 >= value
+//
+// 字符点为 4 到 6 位大写十六进制，位数相同时字符串顺序即数值顺序。
+func hexGTE(field, value string) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{field: primitive.Regex{Pattern: fmt.Sprintf("^[0-9A-F]{%d,}$", len(value)+1)}},
+		bson.M{field: bson.M{"$gte": value, "$regex": primitive.Regex{Pattern: fmt.Sprintf("^[0-9A-F]{%d}$", len(value))}}},
+	}}
+}
+
+// hexLTE 字符点字段按数值 <= value
+func hexLTE(field, value string) bson.M {
+	conditions := bson.A{
+		bson.M{field: bson.M{"$lte": value, "$regex": primitive.Regex{Pattern: fmt.Sprintf("^[0-9A-F]{%d}$", len(value))}}},
+	}
+	if len(value) > 4 {
+		conditions = append(conditions, bson.M{field: primitive.Regex{Pattern: fmt.Sprintf("^[0-9A-F]{4,%d}$", len(value)-1)}})
+	}
+	return bson.M{"$or": conditions}
+}
+
+// parseAge 将 "15.1" 解析为可比较的整数
+func parseAge(age string) (int, error) {
+	major, minor, _ := strings.Cut(age, ".")
+	m, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q", age)
+	}
+	n, err := strconv.Atoi(minor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q", age)
+	}
+	return m*100 + n, nil
+}
+
+// ageInRange 检查 age 是否在闭区间内，未分配的字符（age 为 unassigned 等非数字值）不匹配
+func ageInRange(age, min, max string) bool {
+	v, err := parseAge(age)
+	if err != nil {
+		return false
+	}
+	if lo, err := parseAge(min); err == nil && v < lo {
+		return false
+	}
+	if hi, err := parseAge(max); err == nil && v > hi {
+		return false
+	}
+	return true
+}
+
+// BoolProperties 返回可用于 Property 的属性名
+func BoolProperties() []string {
+	names := make([]string, 0, len(boolProperties))
+	for name := range boolProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toA 转换为 bson.A
+func toA(conditions []bson.M) bson.A {
+	a := make(bson.A, len(conditions))
+	for i, c := range conditions {
+		a[i] = c
+	}
+	return a
+}
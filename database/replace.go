@@ -0,0 +1,264 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"udc2mongo/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ImportMode 替换 ucd、code_points 和 blocks 的方式
+//
+// 没有事务方式：完整导入的字符点文档远多于单个事务建议的 1000 个，也常常超过 transactionLifetimeLimitSeconds。
+type ImportMode string
+
+const (
+	// ImportStaging 写入临时集合，全部成功后改名替换原集合，失败或中断时原集合保持不变
+	ImportStaging ImportMode = "staging"
+	// ImportDirect 逐个集合清空再写入，中途失败会留下新旧混杂的数据
+	ImportDirect ImportMode = "direct"
+)
+
+const (
+	stagingSuffix  = "_staging"
+	previousSuffix = "_previous"
+)
+
+// ParseImportMode 解析导入方式，空字符串表示 staging
+func ParseImportMode(s string) (ImportMode, error) {
+	switch mode := ImportMode(s); mode {
+	case "":
+		return ImportStaging, nil
+	case ImportStaging, ImportDirect:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown import mode %q", s)
+	}
+}
+
+// SaveAll 依次保存UCD主文档、字符点和块
+//
+// 导入被中断时清空已开始写入的集合，避免留下不完整的集合；还没写到的集合保留原有数据。
+func SaveAll(ctx context.Context, s Store, ucd *model.UCD, codePoints []model.CodePoint, blocks []model.Block) error {
+	started, err := saveAll(ctx, s, ucd, codePoints, blocks)
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	// 原 ctx 已取消，清理使用新的 ctx
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if clearErr := s.Clear(cleanupCtx, started...); clearErr != nil {
+		return fmt.Errorf("%w (cleanup failed: %v)", err, clearErr)
+	}
+	return err
+}
+
+// saveAll 依次保存UCD主文档、字符点和块，返回已开始写入的集合
+func saveAll(ctx context.Context, s Store, ucd *model.UCD, codePoints []model.CodePoint, blocks []model.Block) ([]string, error) {
+	// 保存UCD主文档
+	started := []string{"ucd"}
+	err := s.SaveUCD(ctx, ucd)
+	if err != nil {
+		return started, fmt.Errorf("error saving UCD: %w", err)
+	}
+
+	// 保存字符点
+	started = append(started, "code_points")
+	err = s.SaveCodePoints(ctx, codePoints)
+	if err != nil {
+		return started, fmt.Errorf("error saving code points: %w", err)
+	}
+
+	// 保存块
+	started = append(started, "blocks")
+	err = s.SaveBlocks(ctx, blocks)
+	if err != nil {
+		return started, fmt.Errorf("error saving blocks: %w", err)
+	}
+
+	return started, nil
+}
+
+// ReplaceAll 按 mode 替换全部数据
+func (mc *MongoClient) ReplaceAll(ctx context.Context, ucd *model.UCD, codePoints []model.CodePoint, blocks []model.Block, mode ImportMode) (err error) {
+	defer mc.observe("ReplaceAll", time.Now(), &err)
+
+	mc.logger.Info("replacing data", "mode", mode)
+	if mode == ImportDirect {
+		return SaveAll(ctx, mc, ucd, codePoints, blocks)
+	}
+	return mc.replaceViaStaging(ctx, ucd, codePoints, blocks)
+}
+
+// stagingPair 原集合和对应的临时集合
+type stagingPair struct {
+	target, staging *mongo.Collection
+}
+
+// replaceViaStaging 写入带相同校验和索引的临时集合，成功后改名替换原集合
+//
+// 写入或替换失败时删除临时集合，原集合保持不变。
+func (mc *MongoClient) replaceViaStaging(ctx context.Context, ucd *model.UCD, codePoints []model.CodePoint, blocks []model.Block) error {
+	staging := *mc
+	staging.ucd = mc.database.Collection(mc.ucd.Name() + stagingSuffix)
+	staging.CodePoints = mc.database.Collection(mc.CodePoints.Name() + stagingSuffix)
+	staging.blocks = mc.database.Collection(mc.blocks.Name() + stagingSuffix)
+
+	pairs := []stagingPair{
+		{mc.ucd, staging.ucd},
+		{mc.CodePoints, staging.CodePoints},
+		{mc.blocks, staging.blocks},
+	}
+
+	err := func() error {
+		for _, p := range pairs {
+			if err := mc.createStagingCollection(ctx, p.target, p.staging); err != nil {
+				return err
+			}
+		}
+		if err := staging.CreateIndexes(ctx); err != nil {
+			return err
+		}
+		if _, err := saveAll(ctx, &staging, ucd, codePoints, blocks); err != nil {
+			return err
+		}
+		return mc.swapStaging(ctx, pairs)
+	}()
+	if err != nil {
+		// 原 ctx 可能已取消，清理使用新的 ctx
+		cleanupCtx, cancel := withTimeout(context.Background(), mc.timeouts.Operation)
+		defer cancel()
+		for _, p := range pairs {
+			p.staging.Drop(cleanupCtx)
+		}
+		return err
+	}
+	return nil
+}
+
+// swapStaging 用临时集合替换原集合
+//
+// 原集合先改名为 *_previous，再把临时集合改名为原集合，最后删除 *_previous。
+// 每次改名各自是原子的，但多次改名之间没有原子性：任何一次失败时，按相反顺序撤销已完成的改名，
+// 原集合回到原来的名字，临时集合也回到 *_staging。
+func (mc *MongoClient) swapStaging(ctx context.Context, pairs []stagingPair) error {
+	mc.logger.Info("swapping staging collections into place")
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	names := make([]string, len(pairs))
+	for i, p := range pairs {
+		names[i] = p.target.Name()
+	}
+	existing, err := mc.database.ListCollectionNames(ctx, bson.M{"name": bson.M{"$in": names}})
+	if err != nil {
+		return fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	var undo []func(ctx context.Context) error
+	rollback := func(err error) error {
+		// 原 ctx 可能已取消，撤销使用新的 ctx
+		rollbackCtx, cancel := withTimeout(context.Background(), mc.timeouts.Operation)
+		defer cancel()
+
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](rollbackCtx); undoErr != nil {
+				return fmt.Errorf("%w (rollback failed: %v)", err, undoErr)
+			}
+		}
+		return err
+	}
+
+	for _, p := range pairs {
+		target, previous := p.target.Name(), p.target.Name()+previousSuffix
+		if !slices.Contains(existing, target) {
+			continue
+		}
+		if err := mc.renameCollection(ctx, target, previous, true); err != nil {
+			return rollback(err)
+		}
+		undo = append(undo, func(ctx context.Context) error {
+			return mc.renameCollection(ctx, previous, target, false)
+		})
+	}
+
+	for _, p := range pairs {
+		target, staging := p.target.Name(), p.staging.Name()
+		if err := mc.renameCollection(ctx, staging, target, false); err != nil {
+			return rollback(err)
+		}
+		undo = append(undo, func(ctx context.Context) error {
+			return mc.renameCollection(ctx, target, staging, false)
+		})
+	}
+
+	// 新数据已经就位，删除失败只留下 *_previous，下次导入改名时会覆盖
+	for _, p := range pairs {
+		previous := p.target.Name() + previousSuffix
+		if err := mc.database.Collection(previous).Drop(ctx); err != nil {
+			mc.logger.Warn("failed to drop previous collection", "collection", previous, "error", err)
+		}
+	}
+	return nil
+}
+
+// renameCollection 在当前数据库内重命名集合，dropTarget 为 true 时覆盖已有的目标集合
+func (mc *MongoClient) renameCollection(ctx context.Context, from, to string, dropTarget bool) error {
+	err := mc.client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "renameCollection", Value: mc.database.Name() + "." + from},
+		{Key: "to", Value: mc.database.Name() + "." + to},
+		{Key: "dropTarget", Value: dropTarget},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", from, to, err)
+	}
+	return nil
+}
+
+// createStagingCollection 重建临时集合，并复制原集合的校验设置
+func (mc *MongoClient) createStagingCollection(ctx context.Context, target, staging *mongo.Collection) error {
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	if err := staging.Drop(ctx); err != nil {
+		return fmt.Errorf("failed to drop %s: %w", staging.Name(), err)
+	}
+
+	specs, err := mc.database.ListCollectionSpecifications(ctx, bson.M{"name": target.Name()})
+	if err != nil {
+		return fmt.Errorf("failed to read options of %s: %w", target.Name(), err)
+	}
+
+	opts := options.CreateCollection()
+	if len(specs) > 0 && specs[0].Options != nil {
+		var existing struct {
+			Validator        bson.Raw `bson:"validator"`
+			ValidationLevel  string   `bson:"validationLevel"`
+			ValidationAction string   `bson:"validationAction"`
+		}
+		if err := bson.Unmarshal(specs[0].Options, &existing); err != nil {
+			return fmt.Errorf("failed to decode options of %s: %w", target.Name(), err)
+		}
+		if existing.Validator != nil {
+			opts.SetValidator(existing.Validator)
+		}
+		if existing.ValidationLevel != "" {
+			opts.SetValidationLevel(existing.ValidationLevel)
+		}
+		if existing.ValidationAction != "" {
+			opts.SetValidationAction(existing.ValidationAction)
+		}
+	}
+
+	if err := mc.database.CreateCollection(ctx, staging.Name(), opts); err != nil {
+		return fmt.Errorf("failed to create %s: %w", staging.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"udc2mongo/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// closedValueSets 取值封闭的属性，空字符串表示 XML 中没有该属性（例如 unihan 变体）
+//
+// gc、bc、ea、dt、jt、lb、vo、InCB 和各个 break 属性在新版本中会增加取值，不列在这里，
+// 否则新版本的数据会被 strict/error 校验拒绝；其中 aliasValueSets 的取值来自导入版本的别名表。
+//
+// See: https://www.unicode.org/Public/UCD/latest/ucd/PropertyValueAliases.txt
+var closedValueSets = map[string][]string{
+	"bidi_paired_bracket_type": {"o", "c", "n"},
+	"numeric_type":             {"None", "De", "Di", "Nu"},
+	"hangul_syllable_type":     {"L", "V", "T", "LV", "LVT", "NA"},
+	"nfc_qc":                   {"Y", "N", "M"},
+	"nfd_qc":                   {"Y", "N"},
+	"nfkc_qc":                  {"Y", "N", "M"},
+	"nfkd_qc":                  {"Y", "N"},
+	"variant": {
+		string(model.VariantAll), string(model.VariantNoUnihan),
+		string(model.VariantUnihan), string(model.VariantCombined),
+	},
+}
+
+// aliasValueSets 取值随版本增加的属性，enum 由导入版本的 PropertyValueAliases.txt 生成，键为字段名，值为属性短名
+var aliasValueSets = map[string]string{
+	"general_category": "gc",
+	"bidi_class":       "bc",
+	"script":           "sc",
+	"line_break":       "lb",
+	"east_asian_width": "ea",
+}
+
+const (
+	codePointPattern         = "^[0-9A-F]{4,6}$"
+	optionalCodePointPattern = "^([0-9A-F]{4,6})?$"
+)
+
+// ValidationOptions 集合校验选项
+type ValidationOptions struct {
+	Level   string                 // off, moderate 或 strict
+	Action  string                 // error 或 warn
+	Aliases *model.PropertyAliases // 导入版本的别名表，为 aliasValueSets 中的字段生成 enum，nil 时只检查类型
+}
+
+// ParseValidationOptions 解析校验级别和动作，空字符串使用 strict 和 error
+func ParseValidationOptions(level, action string) (ValidationOptions, error) {
+	opts := ValidationOptions{Level: "strict", Action: "error"}
+	switch level {
+	case "":
+	case "off", "moderate", "strict":
+		opts.Level = level
+	default:
+		return opts, fmt.Errorf("unknown validation level %q", level)
+	}
+	switch action {
+	case "":
+	case "error", "warn":
+		opts.Action = action
+	default:
+		return opts, fmt.Errorf("unknown validation action %q", action)
+	}
+	return opts, nil
+}
+
+// CreateValidators 为 ucd、code_points、blocks 和 property_aliases 安装由模型生成的 $jsonSchema 校验
+func (mc *MongoClient) CreateValidators(ctx context.Context, opts ValidationOptions) (err error) {
+	defer mc.observe("CreateValidators", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	mc.logger.Info("installing collection validators", "level", opts.Level, "action", opts.Action)
+
+	enums := valueSets(opts.Aliases)
+	validators := []struct {
+		name   string
+		schema bson.M
+	}{
+		{mc.ucd.Name(), JSONSchema(reflect.TypeOf(model.UCD{}), nil, enums)},
+		{mc.CodePoints.Name(), JSONSchema(reflect.TypeOf(model.CodePoint{}), map[string]string{
+			"cp":       optionalCodePointPattern,
+			"first_cp": optionalCodePointPattern,
+			"last_cp":  optionalCodePointPattern,
+		}, enums)},
+		{mc.blocks.Name(), JSONSchema(reflect.TypeOf(model.Block{}), map[string]string{
+			"first_cp": codePointPattern,
+			"last_cp":  codePointPattern,
+		}, enums)},
+		{mc.aliases.Name(), JSONSchema(reflect.TypeOf(model.PropertyAlias{}), nil, nil)},
+	}
+
+	existing, err := mc.database.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	for _, v := range validators {
+		validator := bson.M{"$jsonSchema": v.schema}
+
+		if !containsString(existing, v.name) {
+			err := mc.database.CreateCollection(ctx, v.name, options.CreateCollection().
+				SetValidator(validator).
+				SetValidationLevel(opts.Level).
+				SetValidationAction(opts.Action))
+			if err != nil {
+				return fmt.Errorf("failed to create collection %s: %w", v.name, err)
+			}
+			continue
+		}
+
+		err := mc.database.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: v.name},
+			{Key: "validator", Value: validator},
+			{Key: "validationLevel", Value: opts.Level},
+			{Key: "validationAction", Value: opts.Action},
+		}).Err()
+		if err != nil {
+			return fmt.Errorf("failed to install validator on %s: %w", v.name, err)
+		}
+	}
+
+	mc.logger.Info("validators installed")
+	return nil
+}
+
+var (
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+	timeValType  = reflect.TypeOf(time.Time{})
+)
+
+// valueSets closedValueSets 加上由别名表生成的 aliasValueSets，aliases 为 nil 时只有前者
+func valueSets(aliases *model.PropertyAliases) map[string][]string {
+	sets := make(map[string][]string, len(closedValueSets)+len(aliasValueSets))
+	for name, values := range closedValueSets {
+		sets[name] = values
+	}
+	if aliases == nil {
+		return sets
+	}
+	for name, property := range aliasValueSets {
+		if values := aliases.ShortValues(property); len(values) > 0 {
+			sets[name] = values
+		}
+	}
+	return sets
+}
+
+// JSONSchema 由 bson 标签生成 $jsonSchema，未标记 omitempty 的字段为必填
+//
+// patterns 为字符串字段指定正则约束；enums 为字符串字段指定取值，空字符串总是允许。
+func JSONSchema(t reflect.Type, patterns map[string]string, enums map[string][]string) bson.M {
+	properties := bson.M{}
+	var required []string
+	collectSchema(t, patterns, enums, properties, &required)
+
+	schema := bson.M{
+		"bsonType":   "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// collectSchema 递归展开 inline 结构体
+func collectSchema(t reflect.Type, patterns map[string]string, enums map[string][]string, properties bson.M, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("bson")
+		name, flags, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && strings.Contains(flags, "inline") {
+			collectSchema(field.Type, patterns, enums, properties, required)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name) // 与驱动默认的字段名一致
+		}
+
+		properties[name] = fieldSchema(name, field.Type, patterns, enums)
+		if !strings.Contains(flags, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// fieldSchema 单个字段的 schema
+func fieldSchema(name string, t reflect.Type, patterns map[string]string, enums map[string][]string) bson.M {
+	switch {
+	case t == objectIDType:
+		return bson.M{"bsonType": "objectId"}
+	case t == timeValType:
+		return bson.M{"bsonType": "date"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		schema := bson.M{"bsonType": "string"}
+		if values, ok := enums[name]; ok {
+			schema["enum"] = append([]string{""}, values...)
+		}
+		if pattern, ok := patterns[name]; ok {
+			schema["pattern"] = pattern
+		}
+		return schema
+	case reflect.Bool:
+		return bson.M{"bsonType": "bool"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return bson.M{"bsonType": bson.A{"int", "long"}}
+	case reflect.Slice:
+		// nil 切片会被编码为 null
+		return bson.M{
+			"bsonType": bson.A{"array", "null"},
+			"items":    fieldSchema(name, t.Elem(), patterns, enums),
+		}
+	case reflect.Struct:
+		return JSONSchema(t, patterns, enums)
+	default:
+		return bson.M{}
+	}
+}
+
+// containsString 检查列表中是否包含字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"udc2mongo/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const textIndexName = "name_text"
+
+// SearchResult 搜索结果，Score 为文本相关度，前缀匹配的结果为 0
+type SearchResult struct {
+	model.CodePoint `bson:",inline"`
+	Score           float64 `bson:"score" json:"score"`
+}
+
+// SearchCodePoints 在 name、name1、name_aliases.alias 和 k_definition 中搜索
+//
+// 先按文本索引的相关度排序，结果不足 limit 时再用 name 前缀匹配补充，用于自动补全。
+func (mc *MongoClient) SearchCodePoints(ctx context.Context, query string, limit int) (_ []SearchResult, err error) {
+	defer mc.observe("SearchCodePoints", time.Now(), &err)
+
+	ctx, cancel := withTimeout(ctx, mc.timeouts.Operation)
+	defer cancel()
+
+	query = strings.TrimSpace(query)
+	if query == "" || limit <= 0 {
+		return nil, nil
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetLimit(int64(limit))
+
+	cursor, err := mc.CodePoints.Find(ctx, bson.M{"$text": bson.M{"$search": query}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search code points: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []SearchResult
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+
+	if len(results) >= limit {
+		return results, nil
+	}
+
+	// 名称均为大写，前缀查询可以使用 name 索引
+	seen := make([]primitive.ObjectID, 0, len(results))
+	for _, r := range results {
+		seen = append(seen, r.ID)
+	}
+	prefix := bson.M{
+		"name": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.ToUpper(query))},
+		"_id":  bson.M{"$nin": seen},
+	}
+
+	cursor, err = mc.CodePoints.Find(ctx, prefix, options.Find().
+		SetSort(bson.M{"name": 1}).
+		SetLimit(int64(limit-len(results))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search code point names: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var prefixResults []SearchResult
+	err = cursor.All(ctx, &prefixResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+
+	return append(results, prefixResults...), nil
+}
@@ -0,0 +1,657 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"udc2mongo/model"
+	"udc2mongo/progress"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteClient 将UCD数据写入单个SQLite文件，用于离线查询
+type SQLiteClient struct {
+	db       *sql.DB
+	logger   *slog.Logger
+	progress progress.Reporter
+}
+
+// sqliteSchemaVersion 当前的表结构版本，保存在 PRAGMA user_version 中
+//
+// 1：code_points、name_aliases 和 blocks；2：blocks.alias；3：property_aliases，以及之后随模型增加的 code_points 列。
+const sqliteSchemaVersion = 3
+
+func NewSQLiteClient(path string, opts ClientOptions) (*SQLiteClient, error) {
+	// 每个连接都启用外键，name_aliases 的 ON DELETE CASCADE 才会生效
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", path+sep+"_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	// SQLite 只允许一个写连接
+	db.SetMaxOpenConns(1)
+
+	sc := &SQLiteClient{db: db, logger: opts.logger(), progress: opts.progress()}
+	if err := sc.createTables(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := sc.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return sc, nil
+}
+
+func (sc *SQLiteClient) Close() error {
+	return sc.db.Close()
+}
+
+// sqliteColumn code_points 表的一列，对应 model.CodePoint 的一个字段
+type sqliteColumn struct {
+	name  string
+	index []int
+	kind  reflect.Kind
+	time  bool
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// codePointColumns 由 bson 标签生成的 code_points 列，name_aliases 存在子表中
+var codePointColumns = collectColumns(reflect.TypeOf(model.CodePoint{}), nil)
+
+// collectColumns 递归展开 inline 结构体
+func collectColumns(t reflect.Type, prefix []int) []sqliteColumn {
+	var columns []sqliteColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, prefix...), i)
+		name, _, _ := strings.Cut(field.Tag.Get("bson"), ",")
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, collectColumns(field.Type, index)...)
+			continue
+		}
+		if name == "" || name == "-" || name == "_id" || name == "name_aliases" {
+			continue
+		}
+
+		columns = append(columns, sqliteColumn{
+			name:  name,
+			index: index,
+			kind:  field.Type.Kind(),
+			time:  field.Type == timeType,
+		})
+	}
+	return columns
+}
+
+// sqlType 列的 SQLite 类型
+func (c sqliteColumn) sqlType() string {
+	switch {
+	case c.time, c.kind == reflect.String:
+		return "TEXT"
+	default:
+		return "INTEGER"
+	}
+}
+
+// value 取出字段值用于写入
+func (c sqliteColumn) value(cp *model.CodePoint) any {
+	v := reflect.ValueOf(cp).Elem().FieldByIndex(c.index)
+	switch {
+	case c.time:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano)
+	case c.kind == reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case c.kind == reflect.String:
+		return v.String()
+	default:
+		return v.Int()
+	}
+}
+
+// holder 返回用于扫描该列的容器
+func (c sqliteColumn) holder() any {
+	if c.sqlType() == "TEXT" {
+		return new(sql.NullString)
+	}
+	return new(sql.NullInt64)
+}
+
+// assign 将扫描结果写回字段
+func (c sqliteColumn) assign(cp *model.CodePoint, holder any) {
+	v := reflect.ValueOf(cp).Elem().FieldByIndex(c.index)
+	switch h := holder.(type) {
+	case *sql.NullString:
+		if c.time {
+			t, _ := time.Parse(time.RFC3339Nano, h.String)
+			v.Set(reflect.ValueOf(t))
+			return
+		}
+		v.SetString(h.String)
+	case *sql.NullInt64:
+		if c.kind == reflect.Bool {
+			v.SetBool(h.Int64 != 0)
+			return
+		}
+		v.SetInt(h.Int64)
+	}
+}
+
+// createTables 创建表结构
+func (sc *SQLiteClient) createTables() error {
+	columns := make([]string, len(codePointColumns))
+	for i, c := range codePointColumns {
+		columns[i] = fmt.Sprintf("%q %s NOT NULL", c.name, c.sqlType())
+	}
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ucd (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			description TEXT NOT NULL,
+			version TEXT NOT NULL,
+			variant TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS code_points (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			` + strings.Join(columns, ",\n\t\t\t") + `
+		)`,
+		`CREATE TABLE IF NOT EXISTS name_aliases (
+			code_point_id INTEGER NOT NULL REFERENCES code_points(id) ON DELETE CASCADE,
+			alias TEXT NOT NULL,
+			type TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS name_aliases_code_point_id ON name_aliases (code_point_id)`,
+		`CREATE TABLE IF NOT EXISTS blocks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			first_cp TEXT NOT NULL,
+			last_cp TEXT NOT NULL,
+			name TEXT NOT NULL,
+			alias TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS property_aliases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			property TEXT NOT NULL,
+			property_name TEXT NOT NULL,
+			short TEXT NOT NULL,
+			long TEXT NOT NULL,
+			others TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS property_aliases_property_short ON property_aliases (property, short)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := sc.db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create SQLite tables: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrate 升级旧版本创建的文件
+//
+// CREATE TABLE IF NOT EXISTS 不会修改已有的表，模型增加字段后，
+// 缺少的列用 ALTER TABLE 补上，默认值为空字符串或 0；之后的导入会覆盖这些行。
+func (sc *SQLiteClient) migrate() error {
+	var version int
+	if err := sc.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read SQLite schema version: %w", err)
+	}
+	if version > sqliteSchemaVersion {
+		return fmt.Errorf("SQLite schema version %d is newer than supported version %d", version, sqliteSchemaVersion)
+	}
+
+	codePointDefaults := make(map[string]string, len(codePointColumns))
+	for _, c := range codePointColumns {
+		if c.sqlType() == "TEXT" {
+			codePointDefaults[c.name] = "TEXT NOT NULL DEFAULT ''"
+		} else {
+			codePointDefaults[c.name] = "INTEGER NOT NULL DEFAULT 0"
+		}
+	}
+	tables := []struct {
+		name    string
+		columns map[string]string
+	}{
+		{"code_points", codePointDefaults},
+		{"blocks", map[string]string{"alias": "TEXT NOT NULL DEFAULT ''"}},
+	}
+	for _, table := range tables {
+		existing, err := sc.tableColumns(table.name)
+		if err != nil {
+			return err
+		}
+		for _, name := range sortedKeys(table.columns) {
+			if existing[name] {
+				continue
+			}
+			sc.logger.Info("adding SQLite column", "table", table.name, "column", name)
+			if _, err := sc.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %q %s", table.name, name, table.columns[name])); err != nil {
+				return fmt.Errorf("failed to add column %s.%s: %w", table.name, name, err)
+			}
+		}
+	}
+
+	if version != sqliteSchemaVersion {
+		if _, err := sc.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+			return fmt.Errorf("failed to write SQLite schema version: %w", err)
+		}
+	}
+	return nil
+}
+
+// tableColumns 表中已有的列
+func (sc *SQLiteClient) tableColumns(table string) (map[string]bool, error) {
+	rows, err := sc.db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (sc *SQLiteClient) SaveUCD(ctx context.Context, ucd *model.UCD) error {
+	now := time.Now().Format(time.RFC3339Nano)
+
+	sc.logger.Debug("clearing existing UCD metadata", "table", "ucd")
+	_, err := sc.db.ExecContext(ctx, `DELETE FROM ucd`)
+	if err != nil {
+		return fmt.Errorf("failed to clear existing UCD data: %w", err)
+	}
+
+	result, err := sc.db.ExecContext(ctx,
+		`INSERT INTO ucd (description, version, variant, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		ucd.Description, ucd.Version, string(ucd.Variant), now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save UCD: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	sc.logger.Info("saved UCD metadata", "id", id, "version", ucd.Version)
+	return nil
+}
+
+func (sc *SQLiteClient) SaveCodePoints(ctx context.Context, codePoints []model.CodePoint) error {
+	if len(codePoints) == 0 {
+		return nil
+	}
+
+	tx, err := sc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sc.logger.Debug("clearing existing code points", "table", "code_points")
+	if _, err := tx.ExecContext(ctx, `DELETE FROM name_aliases`); err != nil {
+		return fmt.Errorf("failed to clear existing name aliases: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM code_points`); err != nil {
+		return fmt.Errorf("failed to clear existing code points: %w", err)
+	}
+
+	names := make([]string, len(codePointColumns))
+	placeholders := make([]string, len(codePointColumns))
+	for i, c := range codePointColumns {
+		names[i] = fmt.Sprintf("%q", c.name)
+		placeholders[i] = "?"
+	}
+
+	insertCodePoint, err := tx.PrepareContext(ctx, `INSERT INTO code_points (`+strings.Join(names, ", ")+
+		`) VALUES (`+strings.Join(placeholders, ", ")+`)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare code point insert: %w", err)
+	}
+	defer insertCodePoint.Close()
+
+	insertAlias, err := tx.PrepareContext(ctx, `INSERT INTO name_aliases (code_point_id, alias, type) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare name alias insert: %w", err)
+	}
+	defer insertAlias.Close()
+
+	sc.logger.Info("inserting code points", "count", len(codePoints))
+	start := time.Now()
+	tracker := sc.progress.Start(progress.Stage{Name: "write code_points", Unit: progress.Documents, Total: int64(len(codePoints))})
+	defer tracker.Done()
+
+	now := time.Now()
+	values := make([]any, len(codePointColumns))
+	for i := range codePoints {
+		cp := &codePoints[i]
+		cp.CreatedAt = now
+		cp.UpdatedAt = now
+
+		for j, c := range codePointColumns {
+			values[j] = c.value(cp)
+		}
+		result, err := insertCodePoint.ExecContext(ctx, values...)
+		if err != nil {
+			return fmt.Errorf("failed to insert code point %s%s: %w", cp.CP, cp.FirstCP, err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to read code point id: %w", err)
+		}
+		for _, alias := range cp.NameAliases {
+			if _, err := insertAlias.ExecContext(ctx, id, alias.Alias, alias.Type); err != nil {
+				return fmt.Errorf("failed to insert name alias %s: %w", alias.Alias, err)
+			}
+		}
+		tracker.Add(1)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit code points: %w", err)
+	}
+
+	sc.logger.Info("saved code points", throughput(len(codePoints), time.Since(start))...)
+	return nil
+}
+
+func (sc *SQLiteClient) SaveBlocks(ctx context.Context, blocks []model.Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	tx, err := sc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sc.logger.Debug("clearing existing blocks", "table", "blocks")
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blocks`); err != nil {
+		return fmt.Errorf("failed to clear existing blocks: %w", err)
+	}
+
+	now := time.Now()
+	for i := range blocks {
+		blocks[i].CreatedAt = now
+		blocks[i].UpdatedAt = now
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO blocks (first_cp, last_cp, name, alias, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			blocks[i].FirstCP, blocks[i].LastCP, blocks[i].Name, blocks[i].Alias,
+			now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert blocks: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit blocks: %w", err)
+	}
+
+	sc.logger.Info("saved blocks", "count", len(blocks))
+	return nil
+}
+
+// SavePropertyAliases 替换 property_aliases 表，others 以空格分隔
+func (sc *SQLiteClient) SavePropertyAliases(ctx context.Context, aliases []model.PropertyAlias) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	tx, err := sc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sc.logger.Debug("clearing existing property aliases", "table", "property_aliases")
+	if _, err := tx.ExecContext(ctx, `DELETE FROM property_aliases`); err != nil {
+		return fmt.Errorf("failed to clear existing property aliases: %w", err)
+	}
+
+	now := time.Now()
+	for i := range aliases {
+		aliases[i].CreatedAt = now
+		aliases[i].UpdatedAt = now
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO property_aliases (property, property_name, short, long, others, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			aliases[i].Property, aliases[i].PropertyName, aliases[i].Short, aliases[i].Long,
+			strings.Join(aliases[i].Others, " "), now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert property aliases: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit property aliases: %w", err)
+	}
+
+	sc.logger.Info("saved property aliases", "count", len(aliases))
+	return nil
+}
+
+// Clear 删除指定表（ucd、code_points 或 blocks）中的全部数据，用于清理中断的导入
+//
+// 清空 code_points 时同时清空 name_aliases。
+func (sc *SQLiteClient) Clear(ctx context.Context, collections ...string) error {
+	for _, name := range collections {
+		var tables []string
+		switch name {
+		case "ucd", "blocks":
+			tables = []string{name}
+		case "code_points":
+			tables = []string{"name_aliases", "code_points"}
+		default:
+			return fmt.Errorf("unknown table %q", name)
+		}
+
+		for _, table := range tables {
+			if _, err := sc.db.ExecContext(ctx, `DELETE FROM `+table); err != nil {
+				return fmt.Errorf("failed to clear %s: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CreateIndexes 创建与 MongoClient.CreateIndexes 相同的索引
+func (sc *SQLiteClient) CreateIndexes(ctx context.Context) error {
+	sc.logger.Info("creating indexes")
+
+	indexes := map[string]string{
+		"code_points_cp":               `ON code_points (cp) WHERE cp != ''`,
+		"code_points_name":             `ON code_points (name)`,
+		"code_points_block":            `ON code_points (block)`,
+		"code_points_block_name":       `ON code_points (block_name)`,
+		"code_points_general_category": `ON code_points (general_category)`,
+		"code_points_script":           `ON code_points (script)`,
+		"code_points_age":              `ON code_points (age)`,
+		"code_points_first_cp_last_cp": `ON code_points (first_cp, last_cp)`,
+		"blocks_first_cp_last_cp":      `ON blocks (first_cp, last_cp)`,
+	}
+
+	for name := range indexes {
+		if _, err := sc.db.ExecContext(ctx, `DROP INDEX IF EXISTS `+name); err != nil {
+			return fmt.Errorf("failed to drop existing index %s: %w", name, err)
+		}
+	}
+	if _, err := sc.db.ExecContext(ctx, `DROP INDEX IF EXISTS blocks_name`); err != nil {
+		return fmt.Errorf("failed to drop existing index blocks_name: %w", err)
+	}
+
+	for name, definition := range indexes {
+		if _, err := sc.db.ExecContext(ctx, `CREATE INDEX `+name+` `+definition); err != nil {
+			return fmt.Errorf("failed to create index %s: %w", name, err)
+		}
+	}
+	if _, err := sc.db.ExecContext(ctx, `CREATE UNIQUE INDEX blocks_name ON blocks (name)`); err != nil {
+		return fmt.Errorf("failed to create blocks indexes: %w", err)
+	}
+
+	sc.logger.Info("indexes created")
+	return nil
+}
+
+func (sc *SQLiteClient) GetCodePointByCP(ctx context.Context, cp string) (*model.CodePoint, error) {
+	codePoints, err := sc.queryCodePoints(ctx, `cp = ?`, cp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find code point %s: %w", cp, err)
+	}
+	if len(codePoints) == 0 {
+		return nil, nil
+	}
+
+	return &codePoints[0], nil
+}
+
+func (sc *SQLiteClient) GetCodePointsByBlock(ctx context.Context, blockName string) ([]model.CodePoint, error) {
+	codePoints, err := sc.queryCodePoints(ctx, `block = ? OR block_name = ?`, blockName, blockName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find code points in block %s: %w", blockName, err)
+	}
+
+	return codePoints, nil
+}
+
+// queryCodePoints 按条件查询字符点并加载别名
+func (sc *SQLiteClient) queryCodePoints(ctx context.Context, where string, args ...any) ([]model.CodePoint, error) {
+	names := make([]string, len(codePointColumns))
+	for i, c := range codePointColumns {
+		names[i] = fmt.Sprintf("%q", c.name)
+	}
+
+	rows, err := sc.db.QueryContext(ctx, `SELECT id, `+strings.Join(names, ", ")+
+		` FROM code_points WHERE `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codePoints []model.CodePoint
+	positions := make(map[int64]int)
+	holders := make([]any, len(codePointColumns)+1)
+	for rows.Next() {
+		var id int64
+		holders[0] = &id
+		for i, c := range codePointColumns {
+			holders[i+1] = c.holder()
+		}
+		if err := rows.Scan(holders...); err != nil {
+			return nil, fmt.Errorf("failed to decode code points: %w", err)
+		}
+
+		var cp model.CodePoint
+		for i, c := range codePointColumns {
+			c.assign(&cp, holders[i+1])
+		}
+		positions[id] = len(codePoints)
+		codePoints = append(codePoints, cp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to decode code points: %w", err)
+	}
+
+	if len(codePoints) == 0 {
+		return codePoints, nil
+	}
+
+	aliasRows, err := sc.db.QueryContext(ctx, `SELECT code_point_id, alias, type FROM name_aliases
+		WHERE code_point_id IN (SELECT id FROM code_points WHERE `+where+`) ORDER BY rowid`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find name aliases: %w", err)
+	}
+	defer aliasRows.Close()
+
+	for aliasRows.Next() {
+		var id int64
+		var alias model.NameAlias
+		if err := aliasRows.Scan(&id, &alias.Alias, &alias.Type); err != nil {
+			return nil, fmt.Errorf("failed to decode name aliases: %w", err)
+		}
+		position, ok := positions[id]
+		if !ok {
+			continue
+		}
+		cp := &codePoints[position]
+		cp.NameAliases = append(cp.NameAliases, alias)
+	}
+
+	return codePoints, aliasRows.Err()
+}
+
+func (sc *SQLiteClient) GetStats(ctx context.Context) (*DatabaseStats, error) {
+	stats := &DatabaseStats{}
+
+	err := sc.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM code_points`).Scan(&stats.CodePointCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count code points: %w", err)
+	}
+
+	err = sc.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM blocks`).Scan(&stats.BlockCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count blocks: %w", err)
+	}
+
+	err = sc.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM ucd`).Scan(&stats.UCDCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count UCD documents: %w", err)
+	}
+
+	rows, err := sc.db.QueryContext(ctx, `SELECT script, COUNT(*) AS count FROM code_points
+		GROUP BY script ORDER BY count DESC LIMIT 10`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate by script: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stat ScriptStat
+		if err := rows.Scan(&stat.Script, &stat.Count); err != nil {
+			return nil, fmt.Errorf("failed to decode script stats: %w", err)
+		}
+		stats.TopScripts = append(stats.TopScripts, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to decode script stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"context"
+	"udc2mongo/model"
+)
+
+// Store 存储后端，MongoClient 和 SQLiteClient 都实现了该接口
+type Store interface {
+	Close() error
+	CreateIndexes(ctx context.Context) error
+	SaveUCD(ctx context.Context, ucd *model.UCD) error
+	SaveCodePoints(ctx context.Context, codePoints []model.CodePoint) error
+	SaveBlocks(ctx context.Context, blocks []model.Block) error
+	SavePropertyAliases(ctx context.Context, aliases []model.PropertyAlias) error
+	Clear(ctx context.Context, collections ...string) error
+	GetCodePointByCP(ctx context.Context, cp string) (*model.CodePoint, error)
+	GetCodePointsByBlock(ctx context.Context, blockName string) ([]model.CodePoint, error)
+	GetStats(ctx context.Context) (*DatabaseStats, error)
+}
+
+var (
+	_ Store = (*MongoClient)(nil)
+	_ Store = (*SQLiteClient)(nil)
+)
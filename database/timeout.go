@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Timeouts MongoDB 操作的超时时间，0 表示只受调用方 ctx 限制
+type Timeouts struct {
+	Connect   time.Duration // 连接、ping 和断开
+	Operation time.Duration // 单次查询、统计、创建索引和校验
+	Bulk      time.Duration // 整个集合的读写，例如 SaveCodePoints、GetAllCodePoints
+}
+
+// DefaultTimeouts 批量读写默认不限时，慢速集群上导入 30 万条数据可能需要几分钟
+var DefaultTimeouts = Timeouts{
+	Connect:   10 * time.Second,
+	Operation: 30 * time.Second,
+}
+
+// ParseTimeouts 解析 time.ParseDuration 格式的超时时间，空字符串使用默认值
+func ParseTimeouts(connect, operation, bulk string) (Timeouts, error) {
+	timeouts := DefaultTimeouts
+	for _, t := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"connect", connect, &timeouts.Connect},
+		{"operation", operation, &timeouts.Operation},
+		{"bulk", bulk, &timeouts.Bulk},
+	} {
+		if t.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(t.value)
+		if err != nil || d < 0 {
+			return timeouts, fmt.Errorf("invalid %s timeout %q", t.name, t.value)
+		}
+		*t.dst = d
+	}
+	return timeouts, nil
+}
+
+// withTimeout 在 ctx 上叠加超时，d 为 0 时只返回可取消的 ctx
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"udc2mongo/model"
+)
+
+// runDiff 比较两个UCD版本，版本可以从网络获取，也可以从已导入的数据库读取
+func runDiff(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	fromVersion := flags.String("from", "", "old UCD version to download, e.g. 15.1.0")
+	toVersion := flags.String("to", "", "new UCD version to download, e.g. 16.0.0")
+	fromDB := flags.String("from-db", "", "MongoDB database holding the old version")
+	toDB := flags.String("to-db", "", "MongoDB database holding the new version")
+	output := flags.String("o", "", "write the change set as JSON to this file")
+	save := flags.Bool("save", false, "store the changes in the ucd_changes collection of MONGODB_DB")
+	flags.Parse(args)
+
+	slog.Info("loading old version")
+	oldVersion, oldCodePoints, oldBlocks, err := loadDiffSide(ctx, *fromVersion, *fromDB)
+	if err != nil {
+		return fmt.Errorf("error loading old version: %w", err)
+	}
+
+	slog.Info("loading new version")
+	newVersion, newCodePoints, newBlocks, err := loadDiffSide(ctx, *toVersion, *toDB)
+	if err != nil {
+		return fmt.Errorf("error loading new version: %w", err)
+	}
+
+	slog.Info("comparing versions", "from", oldVersion, "to", newVersion)
+	changes, err := model.DiffCodePoints(oldCodePoints, newCodePoints)
+	if err != nil {
+		return fmt.Errorf("error comparing code points: %w", err)
+	}
+	changes = append(changes, model.DiffBlocks(oldBlocks, newBlocks)...)
+	changeSet := model.NewChangeSet(oldVersion, newVersion, changes)
+
+	printChangeSummary(changeSet)
+
+	if *output != "" {
+		slog.Info("writing change set", "path", *output)
+		if err := writeChangeSet(*output, changeSet); err != nil {
+			return fmt.Errorf("error writing change set: %w", err)
+		}
+	}
+
+	if *save {
+		slog.Info("saving changes to MongoDB")
+		mongoClient, err := connectMongo(ctx, mongoDBName())
+		if err != nil {
+			return err
+		}
+		defer mongoClient.Close()
+
+		if err := mongoClient.SaveChanges(ctx, changeSet); err != nil {
+			return fmt.Errorf("error saving changes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadDiffSide 加载一侧的数据：指定了数据库就从数据库读取，否则按版本下载
+func loadDiffSide(ctx context.Context, version, dbName string) (string, []model.CodePoint, []model.Block, error) {
+	if dbName != "" {
+		return loadFromMongo(ctx, dbName)
+	}
+
+	if version == "" {
+		return "", nil, nil, fmt.Errorf("either a version or a database is required")
+	}
+
+	codePoints, blocks, err := loadFromXML(ctx, version)
+	return version, codePoints, blocks, err
+}
+
+// printChangeSummary 按对象和类型统计变更
+func printChangeSummary(changeSet *model.ChangeSet) {
+	counts := make(map[string]int)
+	for _, c := range changeSet.Changes {
+		counts[string(c.Target)+" "+string(c.Kind)]++
+	}
+
+	fmt.Printf("Total changes: %d\n", len(changeSet.Changes))
+	for _, target := range []model.ChangeTarget{model.TargetCodePoint, model.TargetBlock} {
+		for _, kind := range []model.ChangeKind{model.ChangeAdded, model.ChangeRemoved, model.ChangeChanged} {
+			if n := counts[string(target)+" "+string(kind)]; n > 0 {
+				fmt.Printf("  %s %s: %d\n", target, kind, n)
+			}
+		}
+	}
+}
+
+// writeChangeSet 将变更集写为JSON文件
+func writeChangeSet(path string, changeSet *model.ChangeSet) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(changeSet); err != nil {
+		return fmt.Errorf("failed to encode change set: %w", err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"udc2mongo/model"
+)
+
+// runDryRun 下载、解析并处理数据，报告将要写入的内容，不调用任何写入方法
+//
+// 配置了 MONGODB_URI 时只读取目标数据库，报告与当前数据的差异。
+func runDryRun(ctx context.Context, version string, variant model.Variant) error {
+	run := &model.ImportRun{Version: version, Variant: variant, StartedAt: time.Now()}
+
+	slog.Info("loading Unicode data", "version", version, "variant", variant)
+	ucd, err := loadUCD(ctx, version, variant, run)
+	if err != nil {
+		return fmt.Errorf("error loading UCD: %w", err)
+	}
+
+	slog.Info("processing data")
+	codePoints, blocks, report, err := model.ProcessUCDForMongoDB(ucd)
+	if err != nil {
+		return fmt.Errorf("error processing data: %w", err)
+	}
+	aliases, err := loadPropertyAliases(ctx, version, codePoints, blocks, report, run)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Dry run of UCD %s (%s), nothing will be written\n", version, variant)
+
+	fmt.Println("\nEntries by kind:")
+	counts := model.CountRepertoire(ucd.Repertoire)
+	for _, kind := range []string{"char", "reserved", "noncharacter", "surrogate"} {
+		fmt.Printf("  %-14s %d\n", kind, counts[kind])
+	}
+	fmt.Printf("  %-14s %d\n", "blocks", len(blocks))
+	fmt.Printf("  %-14s %d\n", "value aliases", len(aliases.Values))
+
+	byBlock, byScript := make(map[string]int), make(map[string]int)
+	for i := range codePoints {
+		n := codePointCount(&codePoints[i])
+		byBlock[codePoints[i].Block] += n
+		byScript[codePoints[i].Script] += n
+	}
+	printCounts("Code points by block:", byBlock)
+	printCounts("Code points by script:", byScript)
+
+	printValidationReport(report)
+
+	if os.Getenv("STORAGE_BACKEND") == "sqlite" {
+		slog.Info("comparison with stored data is only supported for MongoDB")
+		return nil
+	}
+	if os.Getenv("MONGODB_URI") == "" {
+		slog.Info("MONGODB_URI not set, skipping comparison with stored data")
+		return nil
+	}
+	return printStoredDiff(ctx, version, codePoints, blocks)
+}
+
+// printStoredDiff 只读取目标数据库，报告导入后会产生的变更
+func printStoredDiff(ctx context.Context, version string, codePoints []model.CodePoint, blocks []model.Block) error {
+	dbName := mongoDBName()
+	mongoClient, err := connectMongo(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Close()
+
+	// 空数据库按全部新增处理
+	storedVersion := "(empty)"
+	var storedCodePoints []model.CodePoint
+	var storedBlocks []model.Block
+
+	ucd, err := mongoClient.GetUCD(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading stored UCD: %w", err)
+	}
+	if ucd != nil {
+		storedVersion = ucd.Version
+		storedCodePoints, err = mongoClient.GetAllCodePoints(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading stored code points: %w", err)
+		}
+		storedBlocks, err = mongoClient.GetAllBlocks(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading stored blocks: %w", err)
+		}
+	}
+
+	changes, err := model.DiffCodePoints(storedCodePoints, codePoints)
+	if err != nil {
+		return fmt.Errorf("error comparing code points: %w", err)
+	}
+	changes = append(changes, model.DiffBlocks(storedBlocks, blocks)...)
+
+	fmt.Printf("\nChanges against %s (%s -> %s):\n", dbName, storedVersion, version)
+	printChangeSummary(model.NewChangeSet(storedVersion, version, changes))
+	return nil
+}
+
+// printValidationReport 按类型统计并列出全部校验错误
+func printValidationReport(report *model.ValidationReport) {
+	fmt.Printf("\nValidation errors: %d (%d entries skipped)\n", len(report.Errors), report.Skipped)
+	if len(report.Errors) == 0 {
+		return
+	}
+
+	byKind := make(map[string]int)
+	for _, e := range report.Errors {
+		byKind[string(e.Kind)]++
+	}
+	printCounts("Validation errors by kind:", byKind)
+
+	fmt.Println()
+	for _, e := range report.Errors {
+		fmt.Printf("  %-24s %s\n", e.Kind, e.Error())
+	}
+}
+
+// codePointCount 条目包含的字符点数，范围条目按范围大小计算
+func codePointCount(cp *model.CodePoint) int {
+	if cp.CP != "" {
+		return 1
+	}
+	first, err := model.ParseCodePoint(cp.FirstCP)
+	if err != nil {
+		return 0
+	}
+	last, err := model.ParseCodePoint(cp.LastCP)
+	if err != nil {
+		return 0
+	}
+	return int(last-first) + 1
+}
+
+// printCounts 按数量降序输出统计
+func printCounts(title string, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Printf("\n%s\n", title)
+	for _, k := range keys {
+		label := k
+		if label == "" {
+			label = "(none)"
+		}
+		fmt.Printf("  %-24s %d\n", label, counts[k])
+	}
+}
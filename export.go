@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"udc2mongo/export"
+	"udc2mongo/model"
+)
+
+// runExport 将字符点和块导出为 JSON Lines 或 CSV
+func runExport(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	source := flags.String("source", "xml", "read from the UCD XML (xml) or from MONGODB_DB (mongo)")
+	format := flags.String("format", "jsonl", "output format: jsonl or csv")
+	gzip := flags.Bool("gzip", false, "gzip the output files")
+	output := flags.String("o", "", "code points output file (default code_points.<format>[.gz])")
+	blocksOutput := flags.String("blocks-o", "", "also export blocks to this file")
+	columns := flags.String("columns", strings.Join(export.DefaultColumns, ","), "comma separated CSV columns")
+	listColumns := flags.Bool("list-columns", false, "list the available CSV columns and exit")
+	blocks := flags.String("block", "", "comma separated block filter (blk alias, e.g. ASCII)")
+	scripts := flags.String("script", "", "comma separated script filter (e.g. Latn,Grek)")
+	categories := flags.String("gc", "", "comma separated general category filter (e.g. Lu,Ll)")
+	flags.Parse(args)
+
+	if *listColumns {
+		for _, c := range export.Columns() {
+			fmt.Println(c)
+		}
+		return nil
+	}
+
+	exportFormat, err := export.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	opts := export.Options{
+		Format:  exportFormat,
+		Gzip:    *gzip,
+		Columns: splitList(*columns),
+	}
+
+	if *output == "" {
+		*output = "code_points." + string(exportFormat)
+		if *gzip {
+			*output += ".gz"
+		}
+	}
+
+	var codePoints []model.CodePoint
+	var blockList []model.Block
+	switch *source {
+	case "xml":
+		codePoints, blockList, err = loadFromXML(ctx, ucdVersion())
+	case "mongo":
+		_, codePoints, blockList, err = loadFromMongo(ctx, mongoDBName())
+	default:
+		err = fmt.Errorf("unknown source %q", *source)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading data: %w", err)
+	}
+
+	filter := export.Filter{
+		Blocks:            splitList(*blocks),
+		Scripts:           splitList(*scripts),
+		GeneralCategories: splitList(*categories),
+	}
+	codePoints = export.FilterCodePoints(codePoints, filter)
+
+	slog.Info("exporting code points", "count", len(codePoints), "path", *output)
+	if err := export.WriteCodePointsFile(*output, codePoints, opts); err != nil {
+		return fmt.Errorf("error exporting code points: %w", err)
+	}
+
+	if *blocksOutput != "" {
+		blockList = export.FilterBlocks(blockList, filter)
+		slog.Info("exporting blocks", "count", len(blockList), "path", *blocksOutput)
+		if err := export.WriteBlocksFile(*blocksOutput, blockList, opts); err != nil {
+			return fmt.Errorf("error exporting blocks: %w", err)
+		}
+	}
+
+	slog.Info("export finished")
+	return nil
+}
+
+// splitList 解析逗号分隔的列表
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
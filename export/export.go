@@ -0,0 +1,254 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"udc2mongo/model"
+)
+
+// Format 导出格式
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+)
+
+// ParseFormat 解析导出格式
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatJSONL, FormatCSV:
+		return f, nil
+	case "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q", s)
+	}
+}
+
+// DefaultColumns CSV 默认列
+var DefaultColumns = []string{"cp", "first_cp", "last_cp", "name", "general_category", "block", "script"}
+
+// Options 导出选项
+type Options struct {
+	Format  Format
+	Gzip    bool
+	Columns []string // 仅 CSV 使用，列名为 bson 字段名
+}
+
+// Filter 字符点过滤条件，同一字段内多个值为“或”，不同字段之间为“与”
+type Filter struct {
+	Blocks            []string
+	Scripts           []string
+	GeneralCategories []string
+}
+
+// Match 检查字符点是否满足过滤条件，填写了全名时短名和全名都可以匹配
+func (f Filter) Match(cp *model.CodePoint) bool {
+	return matchAny(f.Blocks, cp.Block, cp.BlockName) &&
+		matchAny(f.Scripts, cp.Script, cp.ScriptName) &&
+		matchAny(f.GeneralCategories, cp.GeneralCategory, cp.GeneralCategoryName)
+}
+
+// FilterCodePoints 返回满足过滤条件的字符点
+func FilterCodePoints(codePoints []model.CodePoint, filter Filter) []model.CodePoint {
+	filtered := make([]model.CodePoint, 0, len(codePoints))
+	for i := range codePoints {
+		if filter.Match(&codePoints[i]) {
+			filtered = append(filtered, codePoints[i])
+		}
+	}
+	return filtered
+}
+
+// FilterBlocks 返回被过滤条件选中的块，只有块条件参与过滤
+func FilterBlocks(blocks []model.Block, filter Filter) []model.Block {
+	if len(filter.Blocks) == 0 {
+		return blocks
+	}
+
+	filtered := make([]model.Block, 0, len(blocks))
+	for _, b := range blocks {
+		if matchAny(filter.Blocks, b.Name, b.Alias) {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered
+}
+
+// matchAny 空列表匹配所有值，否则任意一个候选值在列表中即匹配
+func matchAny(values []string, candidates ...string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		for _, c := range candidates {
+			if v == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteCodePointsFile 将字符点导出到文件
+func WriteCodePointsFile(path string, codePoints []model.CodePoint, opts Options) error {
+	return writeFile(path, opts, func(w io.Writer) error {
+		switch opts.Format {
+		case FormatCSV:
+			return WriteCodePointsCSV(w, codePoints, opts.Columns)
+		default:
+			return WriteCodePointsJSONL(w, codePoints)
+		}
+	})
+}
+
+// WriteBlocksFile 将块导出到文件
+func WriteBlocksFile(path string, blocks []model.Block, opts Options) error {
+	return writeFile(path, opts, func(w io.Writer) error {
+		switch opts.Format {
+		case FormatCSV:
+			return WriteBlocksCSV(w, blocks)
+		default:
+			return WriteBlocksJSONL(w, blocks)
+		}
+	})
+}
+
+// writeFile 创建文件并按需套上 gzip
+func writeFile(path string, opts Options, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var w io.Writer = file
+	var gz *gzip.Writer
+	if opts.Gzip {
+		gz = gzip.NewWriter(file)
+		w = gz
+	}
+
+	if err := write(w); err != nil {
+		return err
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to finish gzip stream: %w", err)
+		}
+	}
+
+	return file.Close()
+}
+
+// WriteCodePointsJSONL 每行一个字符点，使用 json 标签
+func WriteCodePointsJSONL(w io.Writer, codePoints []model.CodePoint) error {
+	encoder := json.NewEncoder(w)
+	for i := range codePoints {
+		if err := encoder.Encode(&codePoints[i]); err != nil {
+			return fmt.Errorf("failed to encode code point: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteBlocksJSONL 每行一个块
+func WriteBlocksJSONL(w io.Writer, blocks []model.Block) error {
+	encoder := json.NewEncoder(w)
+	for i := range blocks {
+		if err := encoder.Encode(&blocks[i]); err != nil {
+			return fmt.Errorf("failed to encode block: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteCodePointsCSV 按指定列导出字符点，列为空时使用 DefaultColumns
+func WriteCodePointsCSV(w io.Writer, codePoints []model.CodePoint, columns []string) error {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+	if err := validateColumns(columns); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	record := make([]string, len(columns))
+	for i := range codePoints {
+		values := codePointValues(&codePoints[i])
+		for j, column := range columns {
+			record[j] = values[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteBlocksCSV 导出块
+func WriteBlocksCSV(w io.Writer, blocks []model.Block) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"first_cp", "last_cp", "name", "alias"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, b := range blocks {
+		if err := writer.Write([]string{b.FirstCP, b.LastCP, b.Name, b.Alias}); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Columns 返回所有可用的 CSV 列
+func Columns() []string {
+	columns := []string{"cp", "first_cp", "last_cp"}
+	for _, v := range model.PropertyValues(&model.CodePointProperties{}) {
+		columns = append(columns, v.Name)
+	}
+	return columns
+}
+
+// validateColumns 检查列名是否存在
+func validateColumns(columns []string) error {
+	known := make(map[string]bool)
+	for _, c := range Columns() {
+		known[c] = true
+	}
+	for _, c := range columns {
+		if !known[c] {
+			return fmt.Errorf("unknown column %q", c)
+		}
+	}
+	return nil
+}
+
+// codePointValues 字符点的全部列值
+func codePointValues(cp *model.CodePoint) map[string]string {
+	values := map[string]string{
+		"cp":       cp.CP,
+		"first_cp": cp.FirstCP,
+		"last_cp":  cp.LastCP,
+	}
+	for _, v := range model.PropertyValues(&cp.CodePointProperties) {
+		values[v.Name] = v.Value
+	}
+	return values
+}
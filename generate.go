@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"udc2mongo/codegen"
+	"udc2mongo/model"
+)
+
+// runGenerate 生成嵌入属性查找表的 Go 源代码
+func runGenerate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	source := flags.String("source", "xml", "read from the UCD XML (xml) or from MONGODB_DB (mongo)")
+	dir := flags.String("dir", "ucdtables", "output directory")
+	pkg := flags.String("package", "", "package name (default: base name of -dir)")
+	properties := flags.String("properties", strings.Join(codegen.DefaultProperties, ","), "comma separated properties (bson field names)")
+	flags.Parse(args)
+
+	var version string
+	var codePoints []model.CodePoint
+	var err error
+	switch *source {
+	case "xml":
+		version = ucdVersion()
+		codePoints, _, err = loadFromXML(ctx, version)
+	case "mongo":
+		version, codePoints, _, err = loadFromMongo(ctx, mongoDBName())
+	default:
+		err = fmt.Errorf("unknown source %q", *source)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading data: %w", err)
+	}
+
+	if *pkg == "" {
+		*pkg = filepath.Base(*dir)
+	}
+
+	slog.Info("generating tables", "properties", *properties)
+	output, err := codegen.Generate(codePoints, codegen.Options{
+		Package:    *pkg,
+		Version:    version,
+		Properties: splitList(*properties),
+	})
+	if err != nil {
+		return fmt.Errorf("error generating tables: %w", err)
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	sourcePath := filepath.Join(*dir, "tables.go")
+	if err := os.WriteFile(sourcePath, output.Source, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", sourcePath, err)
+	}
+
+	testPath := filepath.Join(*dir, "tables_test.go")
+	if err := os.WriteFile(testPath, output.Test, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", testPath, err)
+	}
+
+	slog.Info("tables written", "source", sourcePath, "test", testPath)
+	return nil
+}
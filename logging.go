@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// logOptions 全局日志选项，命令行参数优先于 LOG_LEVEL 和 LOG_FORMAT
+type logOptions struct {
+	Level   string // debug、info、warn 或 error
+	Format  string // text 或 json
+	Verbose bool   // 等同于 debug
+	Quiet   bool   // 只输出错误
+}
+
+// newLogger 创建写入 w 的日志，命令结果仍然输出到 stdout
+func newLogger(w io.Writer, opts logOptions) (*slog.Logger, error) {
+	var level slog.Level
+	switch strings.ToLower(opts.Level) {
+	case "", "info":
+		level = slog.LevelInfo
+	case "debug":
+		level = slog.LevelDebug
+	case "warn", "warning":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		return nil, fmt.Errorf("unknown log level %q", opts.Level)
+	}
+	if opts.Verbose {
+		level = slog.LevelDebug
+	}
+	if opts.Quiet {
+		level = slog.LevelError
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+}
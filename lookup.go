@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"udc2mongo/database"
+)
+
+// runLookup 逐字符列出字符串的属性，没有参数时从标准输入读取
+func runLookup(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("lookup", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the characters as JSON")
+	flags.Parse(args)
+
+	text := strings.Join(flags.Args(), " ")
+	if text == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading standard input: %w", err)
+		}
+		text = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	}
+	if text == "" {
+		return fmt.Errorf("usage: lookup [-json] <text>, or pipe the text to standard input")
+	}
+
+	mongoClient, err := connectMongo(ctx, mongoDBName())
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Close()
+
+	infos, err := mongoClient.LookupString(ctx, text)
+	if err != nil {
+		return fmt.Errorf("error looking up characters: %w", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(infos)
+	}
+
+	for _, info := range infos {
+		char := info.Char
+		if info.Invisible || info.InvalidUTF8 || !info.Found {
+			char = " "
+		}
+		fmt.Printf("  U+%-6s %s %-4s %-4s %-4s %-3s %s%s\n", info.CP, char,
+			info.GeneralCategory, info.Script, info.BidiClass, info.EastAsianWidth, info.Name, characterFlags(info))
+	}
+	return nil
+}
+
+// characterFlags 需要注意的标记，例如 " [invisible, bidi-control]"
+func characterFlags(info database.CharacterInfo) string {
+	var flags []string
+	checks := []struct {
+		set  bool
+		name string
+	}{
+		{!info.Found && !info.InvalidUTF8, "not found"},
+		{info.InvalidUTF8, "invalid UTF-8"},
+		{info.Invisible, "invisible"},
+		{info.DefaultIgnorable, "default-ignorable"},
+		{info.BidiControl, "bidi-control"},
+		{info.Emoji, "emoji"},
+	}
+	for _, c := range checks {
+		if c.set {
+			flags = append(flags, c.name)
+		}
+	}
+	if len(flags) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(flags, ", ") + "]"
+}
@@ -4,135 +4,281 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"udc2mongo/database"
 	"udc2mongo/model"
+	"udc2mongo/progress"
 
 	"github.com/joho/godotenv"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 func main() {
 	// 加载.env文件
-	err := godotenv.Load()
+	envErr := godotenv.Load()
+
+	// 命令之前的全局参数
+	global := flag.NewFlagSet("udc2mongo", flag.ExitOnError)
+	logOpts := logOptions{}
+	global.StringVar(&logOpts.Level, "log-level", os.Getenv("LOG_LEVEL"), "debug, info, warn or error")
+	global.StringVar(&logOpts.Format, "log-format", os.Getenv("LOG_FORMAT"), "text or json")
+	global.BoolVar(&logOpts.Verbose, "v", false, "verbose output, same as -log-level debug")
+	global.BoolVar(&logOpts.Quiet, "q", false, "only log errors")
+	progressMode := global.String("progress", os.Getenv("PROGRESS"), "auto, bar, lines or none")
+	metricsAddr := global.String("metrics-addr", os.Getenv("METRICS_ADDR"), "serve /metrics on this address, e.g. :9090")
+	pushgateway := global.String("pushgateway", os.Getenv("PUSHGATEWAY_URL"), "push metrics to this Pushgateway when the command finishes")
+	global.Parse(os.Args[1:])
+	if logOpts.Quiet {
+		*progressMode = "none"
+	}
+
+	logger, err := newLogger(os.Stderr, logOpts)
 	if err != nil {
-		log.Printf("Warning: Error loading .env file: %v", err)
-		log.Println("Continuing with system environment variables...")
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
+	reporter, err = newReporter(*progressMode)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+	reporter = appMetrics.Progress(reporter)
+
+	if envErr != nil {
+		slog.Debug("no .env file loaded, using system environment variables", "error", envErr)
 	} else {
-		fmt.Println("✓ .env file loaded successfully")
+		slog.Debug(".env file loaded")
+	}
+
+	command, args := "import", global.Args()
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	if *metricsAddr != "" {
+		server := serveMetrics(*metricsAddr)
+		defer server.Close()
+	}
+
+	// Ctrl-C 取消 ctx，再次 Ctrl-C 时立即退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	switch command {
+	case "import":
+		err = runImport(ctx, args)
+	case "diff":
+		err = runDiff(ctx, args)
+	case "export":
+		err = runExport(ctx, args)
+	case "ucdtxt":
+		err = runUcdTxt(ctx, args)
+	case "generate":
+		err = runGenerate(ctx, args)
+	case "search":
+		err = runSearch(ctx, args)
+	case "query":
+		err = runQuery(ctx, args)
+	case "runs":
+		err = runRuns(ctx, args)
+	case "lookup":
+		err = runLookup(ctx, args)
+	case "serve":
+		err = runServe(ctx, args)
+	case "segment":
+		err = runSegment(ctx, args)
+	case "normalize":
+		err = runNormalize(ctx, args)
+	case "case":
+		err = runCase(ctx, args)
+	case "bidi":
+		err = runBidi(ctx, args)
+	default:
+		err = fmt.Errorf("unknown command %q", command)
+	}
+	stop()
+
+	if *pushgateway != "" {
+		job := os.Getenv("PUSHGATEWAY_JOB")
+		if job == "" {
+			job = "udc2mongo"
+		}
+		pushMetrics(*pushgateway, job, command)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		slog.Error("interrupted")
+		os.Exit(130)
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
 	}
+}
 
-	fmt.Println("Unicode Data to MongoDB Processor")
-	fmt.Println("==================================")
+// runImport 下载、解析UCD并导入MongoDB
+func runImport(ctx context.Context, args []string) (err error) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "fetch, parse and validate, then report what would change without writing")
+	flags.Parse(args)
 
-	baseUrl := "https://www.unicode.org/Public/16.0.0/ucdxml/"
+	version := ucdVersion()
 
-	// 获取XML内容（带缓存）
-	fmt.Println("1. Fetching Unicode data...")
-	content, err := fetchUcdXmlContentWithCache(baseUrl)
+	variant, err := model.ParseVariant(os.Getenv("UCD_VARIANT"))
 	if err != nil {
-		fmt.Printf("Error fetching UCD XML content: %v\n", err)
-		return
+		return fmt.Errorf("error reading UCD_VARIANT: %w", err)
+	}
+
+	if *dryRun {
+		return runDryRun(ctx, version, variant)
 	}
-	fmt.Printf("Successfully fetched %d bytes of XML data\n", len(content))
 
-	// 解析XML
-	fmt.Println("\n2. Parsing XML data...")
-	ucd, err := model.ParseUCDXML(content)
+	mode, err := database.ParseImportMode(os.Getenv("IMPORT_MODE"))
 	if err != nil {
-		fmt.Printf("Error parsing XML: %v\n", err)
-		return
+		return fmt.Errorf("error reading IMPORT_MODE: %w", err)
 	}
 
-	// 处理数据
-	fmt.Println("\n3. Processing data for MongoDB...")
-	codePoints, blocks, err := model.ProcessUCDForMongoDB(ucd)
+	run := &model.ImportRun{Version: version, Variant: variant, Mode: string(mode), StartedAt: time.Now()}
+	defer func() {
+		appMetrics.RecordImport(run, err)
+	}()
+
+	// 连接存储后端
+	slog.Info("connecting to storage backend")
+	start := time.Now()
+	store, err := openStore(ctx)
 	if err != nil {
-		fmt.Printf("Error processing data: %v\n", err)
-		return
+		return err
 	}
+	defer store.Close()
+	run.Stage("connect", start)
 
-	// 连接MongoDB
-	fmt.Println("\n4. Connecting to MongoDB...")
-	mongoURI := os.Getenv("MONGODB_URI")
-	if mongoURI == "" {
-		mongoURI = "mongodb://localhost:27017" // 默认本地连接
+	// MongoDB 在 import_runs 中记录本次导入，失败也会记录
+	mongoClient, _ := store.(*database.MongoClient)
+	if mongoClient != nil {
+		if err := mongoClient.StartImportRun(ctx, run); err != nil {
+			return err
+		}
+		defer func() {
+			if finishErr := finishImportRun(mongoClient, run, err); finishErr != nil && err == nil {
+				err = finishErr
+			}
+		}()
 	}
 
-	dbName := os.Getenv("MONGODB_DB")
-	if dbName == "" {
-		dbName = "unicode_db" // 默认数据库名
+	// 获取并解析XML
+	slog.Info("loading Unicode data", "version", version, "variant", variant)
+	ucd, err := loadUCD(ctx, version, variant, run)
+	if err != nil {
+		return fmt.Errorf("error loading UCD: %w", err)
 	}
 
-	mongoClient, err := database.NewMongoClient(mongoURI, dbName)
+	// 处理数据
+	slog.Info("processing data")
+	start = time.Now()
+	codePoints, blocks, report, err := model.ProcessUCDForMongoDB(ucd)
 	if err != nil {
-		fmt.Printf("Error connecting to MongoDB: %v\n", err)
-		return
+		return fmt.Errorf("error processing data: %w", err)
 	}
-	defer mongoClient.Close()
+	run.Stage("process", start)
 
-	fmt.Printf("Connected to MongoDB at %s, database: %s\n", mongoURI, dbName)
+	// 属性别名表
+	aliases, err := loadPropertyAliases(ctx, version, codePoints, blocks, report, run)
+	if err != nil {
+		return err
+	}
+	if len(report.Errors) > 0 {
+		slog.Warn("validation found problems", "errors", len(report.Errors), "skipped", report.Skipped)
+	}
+	slog.Info("processed UCD", "code_points", len(codePoints), "blocks", len(blocks))
+	run.Warnings = model.SummarizeWarnings(report)
+	run.Counts = model.CountRepertoire(ucd.Repertoire)
+	run.Counts["skipped"] = int64(report.Skipped)
 
 	// 创建索引
-	fmt.Println("\n5. Creating database indexes...")
-	err = mongoClient.CreateIndexes()
+	slog.Info("creating database indexes")
+	start = time.Now()
+	err = store.CreateIndexes(ctx)
 	if err != nil {
-		fmt.Printf("Error creating indexes: %v\n", err)
-		return
+		return fmt.Errorf("error creating indexes: %w", err)
 	}
 
-	// 保存数据到MongoDB
-	fmt.Println("\n6. Saving data to MongoDB...")
+	// 安装集合校验
+	if mongoClient != nil {
+		validation, err := database.ParseValidationOptions(
+			os.Getenv("MONGODB_VALIDATION_LEVEL"), os.Getenv("MONGODB_VALIDATION_ACTION"))
+		if err != nil {
+			return err
+		}
+		validation.Aliases = aliases
 
-	// 保存UCD主文档
-	ucd.Version = "16.0.0"
-	err = mongoClient.SaveUCD(ucd)
-	if err != nil {
-		fmt.Printf("Error saving UCD: %v\n", err)
-		return
+		err = mongoClient.CreateValidators(ctx, validation)
+		if err != nil {
+			return fmt.Errorf("error installing validators: %w", err)
+		}
 	}
+	run.Stage("indexes", start)
+
+	// 保存数据
+	slog.Info("saving data")
+	start = time.Now()
 
-	// 保存字符点
-	err = mongoClient.SaveCodePoints(codePoints)
+	ucd.Version = version
+	ucd.Variant = variant
+	err = saveData(ctx, store, ucd, codePoints, blocks, mode)
 	if err != nil {
-		fmt.Printf("Error saving code points: %v\n", err)
-		return
+		return err
 	}
+	run.Stage("save", start)
+	run.Counts["code_points"] = int64(len(codePoints))
+	run.Counts["blocks"] = int64(len(blocks))
 
-	// 保存块
-	err = mongoClient.SaveBlocks(blocks)
+	// 别名表与版本数据分开写入，不参与 IMPORT_MODE 的原子替换
+	err = store.SavePropertyAliases(ctx, aliases.Values)
 	if err != nil {
-		fmt.Printf("Error saving blocks: %v\n", err)
-		return
+		return fmt.Errorf("error saving property aliases: %w", err)
 	}
+	run.Counts["property_aliases"] = int64(len(aliases.Values))
 
 	// 获取统计信息
-	fmt.Println("\n7. Database Statistics:")
-	stats, err := mongoClient.GetStats()
+	slog.Info("collecting database statistics")
+	start = time.Now()
+	stats, err := store.GetStats(ctx)
 	if err != nil {
-		fmt.Printf("Error getting stats: %v\n", err)
-		return
+		return fmt.Errorf("error getting stats: %w", err)
 	}
 
-	fmt.Printf("✓ Total Code Points: %d\n", stats.CodePointCount)
-	fmt.Printf("✓ Total Blocks: %d\n", stats.BlockCount)
-	fmt.Printf("✓ UCD Documents: %d\n", stats.UCDCount)
+	fmt.Println("Database Statistics:")
+	fmt.Printf("  Total Code Points: %d\n", stats.CodePointCount)
+	fmt.Printf("  Total Blocks: %d\n", stats.BlockCount)
+	fmt.Printf("  UCD Documents: %d\n", stats.UCDCount)
 
 	// 详细字符类型统计
-	fmt.Println("\n8. Detailed Character Type Analysis:")
-	err = analyzeCharacterTypes(mongoClient)
-	if err != nil {
-		fmt.Printf("Error analyzing character types: %v\n", err)
-		return
+	if mongoClient != nil {
+		fmt.Println("\nDetailed Character Type Analysis:")
+		err = analyzeCharacterTypes(ctx, mongoClient)
+		if err != nil {
+			return fmt.Errorf("error analyzing character types: %w", err)
+		}
 	}
+	run.Stage("stats", start)
 
 	if len(stats.TopScripts) > 0 {
 		fmt.Println("\nTop Scripts by Character Count:")
@@ -148,70 +294,316 @@ func main() {
 		}
 	}
 
-	fmt.Println("\n✅ Data successfully imported to MongoDB!")
+	if mongoClient == nil {
+		slog.Info("data imported to SQLite")
+		return nil
+	}
+
+	slog.Info("data imported to MongoDB", "import_run", run.ID.Hex())
 	fmt.Println("\nExample queries you can run:")
 	fmt.Printf("  - Find character by code point: db.code_points.findOne({\"cp\": \"0041\"})\n")
-	fmt.Printf("  - Find characters in Latin block: db.code_points.find({\"block\": \"ASCII\"})\n")
+	fmt.Printf("  - Find characters in Basic Latin block: db.code_points.find({\"block_name\": \"Basic Latin\"}) or ({\"block\": \"ASCII\"})\n")
 	fmt.Printf("  - Find Chinese characters: db.code_points.find({\"script\": \"Hani\"})\n")
+	return nil
 }
 
-const (
-	cacheFile    = "ucd.all.flat.xml"
-	cacheZipFile = "ucd.all.flat.zip"
-)
+// saveData 保存数据，MongoDB 按 mode 替换
+func saveData(ctx context.Context, store database.Store, ucd *model.UCD, codePoints []model.CodePoint, blocks []model.Block, mode database.ImportMode) error {
+	mongoClient, ok := store.(*database.MongoClient)
+	if !ok {
+		return database.SaveAll(ctx, store, ucd, codePoints, blocks)
+	}
+
+	return mongoClient.ReplaceAll(ctx, ucd, codePoints, blocks, mode)
+}
+
+// finishImportRun 记录导入结果，原 ctx 可能已取消，使用新的 ctx
+func finishImportRun(mongoClient *database.MongoClient, run *model.ImportRun, err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	finishErr := mongoClient.FinishImportRun(ctx, run, err)
+	if finishErr != nil && err != nil {
+		slog.Warn("failed to record import run", "error", finishErr)
+	}
+	return finishErr
+}
+
+// ucdVersion 读取要导入的Unicode版本
+func ucdVersion() string {
+	version := os.Getenv("UCD_VERSION")
+	if version == "" {
+		version = "16.0.0" // 默认版本
+	}
+	return version
+}
+
+// ucdBaseUrl 返回指定版本的UCD XML下载地址
+func ucdBaseUrl(version string) string {
+	return "https://www.unicode.org/Public/" + version + "/ucdxml/"
+}
+
+// mongoDBName 读取数据库名
+func mongoDBName() string {
+	dbName := os.Getenv("MONGODB_DB")
+	if dbName == "" {
+		dbName = "unicode_db" // 默认数据库名
+	}
+	return dbName
+}
+
+// clientOptions 存储客户端使用命令行的日志、进度报告和调用指标
+func clientOptions() database.ClientOptions {
+	return database.ClientOptions{Logger: slog.Default(), Progress: reporter, Observer: appMetrics.ObserveCall}
+}
+
+// openStore 按 STORAGE_BACKEND 打开存储后端
+func openStore(ctx context.Context) (database.Store, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "mongo":
+		return connectMongo(ctx, mongoDBName())
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "unicode.db" // 默认文件名
+		}
+
+		sqliteClient, err := database.NewSQLiteClient(path, clientOptions())
+		if err != nil {
+			return nil, fmt.Errorf("error opening SQLite database: %w", err)
+		}
+
+		slog.Info("opened SQLite database", "path", path)
+		return sqliteClient, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
+
+// connectMongo 连接到指定数据库
+func connectMongo(ctx context.Context, dbName string) (*database.MongoClient, error) {
+	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" {
+		mongoURI = "mongodb://localhost:27017" // 默认本地连接
+	}
+
+	timeouts, err := database.ParseTimeouts(os.Getenv("MONGODB_CONNECT_TIMEOUT"),
+		os.Getenv("MONGODB_OPERATION_TIMEOUT"), os.Getenv("MONGODB_BULK_TIMEOUT"))
+	if err != nil {
+		return nil, err
+	}
+
+	bulk, err := database.ParseBulkOptions(os.Getenv("MONGODB_BATCH_SIZE"),
+		os.Getenv("MONGODB_WRITE_CONCURRENCY"), os.Getenv("MONGODB_WRITE_RETRIES"))
+	if err != nil {
+		return nil, err
+	}
+
+	mongoClient, err := database.NewMongoClient(ctx, mongoURI, dbName, timeouts, clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to MongoDB: %w", err)
+	}
+	mongoClient.SetBulkOptions(bulk)
+
+	slog.Info("connected to MongoDB", "uri", mongoURI, "database", dbName)
+	return mongoClient, nil
+}
+
+// loadFromXML 下载并处理指定版本，变体由 UCD_VARIANT 决定，块的别名和全名与导入时一样填写
+func loadFromXML(ctx context.Context, version string) ([]model.CodePoint, []model.Block, error) {
+	variant, err := model.ParseVariant(os.Getenv("UCD_VARIANT"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading UCD_VARIANT: %w", err)
+	}
+
+	ucd, err := loadUCD(ctx, version, variant, &model.ImportRun{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	codePoints, blocks, report, err := model.ProcessUCDForMongoDB(ucd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 别名表只用于填写块名和全名，比较和导出不因别名表不可用而失败
+	if _, err := loadPropertyAliases(ctx, version, codePoints, blocks, report, &model.ImportRun{}); err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, err
+		}
+		slog.Warn("block aliases and long names are left empty", "error", err)
+	}
+	return codePoints, blocks, nil
+}
+
+// loadFromMongo 从已导入的数据库读取版本、字符点和块
+func loadFromMongo(ctx context.Context, dbName string) (string, []model.CodePoint, []model.Block, error) {
+	mongoClient, err := connectMongo(ctx, dbName)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	defer mongoClient.Close()
+
+	ucd, err := mongoClient.GetUCD(ctx)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if ucd == nil {
+		return "", nil, nil, fmt.Errorf("no UCD metadata in database %s", dbName)
+	}
+
+	codePoints, err := mongoClient.GetAllCodePoints(ctx)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	blocks, err := mongoClient.GetAllBlocks(ctx)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	return ucd.Version, codePoints, blocks, nil
+}
+
+// loadUCD 获取并解析指定变体的UCD数据，combined 会合并 nounihan 和 unihan
+//
+// 源文件和各阶段耗时记录在 run 中。
+func loadUCD(ctx context.Context, version string, variant model.Variant, run *model.ImportRun) (*model.UCD, error) {
+	if variant != model.VariantCombined {
+		return fetchAndParseUCD(ctx, version, variant, run)
+	}
+
+	ucd, err := fetchAndParseUCD(ctx, version, model.VariantNoUnihan, run)
+	if err != nil {
+		return nil, err
+	}
+
+	unihan, err := fetchAndParseUCD(ctx, version, model.VariantUnihan, run)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("merging Unihan properties")
+	start := time.Now()
+	unmatched, err := model.MergeUnihan(ucd, unihan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge Unihan data: %w", err)
+	}
+	if unmatched > 0 {
+		slog.Warn("Unihan entries have no matching code point", "count", unmatched)
+	}
+	run.Stage("merge", start)
+
+	ucd.Variant = variant
+	return ucd, nil
+}
+
+// fetchAndParseUCD 获取并解析单个变体的XML文件
+func fetchAndParseUCD(ctx context.Context, version string, variant model.Variant, run *model.ImportRun) (*model.UCD, error) {
+	slog.Info("fetching Unicode data", "file", variant.FileName())
+	start := time.Now()
+	content, source, err := fetchUcdXmlContentWithCache(ctx, version, variant.FileName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch UCD XML content: %w", err)
+	}
+	slog.Info("fetched XML data", "bytes", len(content), "cached", source.Cached)
+	run.Stage("download", start)
+	run.Sources = append(run.Sources, source)
+
+	slog.Info("parsing XML data")
+	start = time.Now()
+	ucd, err := model.ParseUCDXML(content, reporter)
+	if err != nil {
+		appMetrics.ParseErrors.Inc()
+		return nil, err
+	}
+	run.Stage("parse", start)
+	counts := model.CountRepertoire(ucd.Repertoire)
+	slog.Info("parsed UCD XML", "description", ucd.Description,
+		"char", counts["char"], "reserved", counts["reserved"],
+		"noncharacter", counts["noncharacter"], "surrogate", counts["surrogate"],
+		"blocks", len(model.ExtractBlocks(ucd)))
+
+	ucd.Variant = variant
+	return ucd, nil
+}
 
 // fetchUcdXmlContentWithCache 带缓存的数据获取函数
-func fetchUcdXmlContentWithCache(baseUrl string) ([]byte, error) {
+//
+// 缓存文件名包含版本，例如 ucd-16.0.0-ucd.all.flat.xml，diff 比较两个版本时不会读到另一个版本的缓存。
+// 下载的ZIP文件也会缓存，用于记录源文件的哈希。
+func fetchUcdXmlContentWithCache(ctx context.Context, version, fileName string) ([]byte, model.ImportSource, error) {
+	source := model.ImportSource{File: fileName + ".zip"}
+
+	fileUrl, err := url.JoinPath(ucdBaseUrl(version), fileName+".zip")
+	if err != nil {
+		return nil, source, fmt.Errorf("failed to construct file URL: %w", err)
+	}
+	source.URL = fileUrl
 	cacheDir := os.TempDir()
 
 	// 确保缓存目录存在
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		return nil, source, fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	cacheFilePath := filepath.Join(cacheDir, cacheFile)
-	cacheZipPath := filepath.Join(cacheDir, cacheZipFile)
+	cacheFilePath := filepath.Join(cacheDir, "ucd-"+version+"-"+fileName+".xml")
+	cacheZipPath := filepath.Join(cacheDir, "ucd-"+version+"-"+fileName+".zip")
 
 	// 检查XML缓存是否存在
 	if isCacheValid(cacheFilePath) {
-		fmt.Println("Using cached XML data...")
-		return os.ReadFile(cacheFilePath)
+		slog.Debug("using cached XML data", "path", cacheFilePath)
+		source.Cached = true
+		source.SHA256 = fileSHA256(cacheZipPath)
+		content, err := os.ReadFile(cacheFilePath)
+		return content, source, err
 	}
 
 	// 检查ZIP缓存是否存在，如果存在就解压
 	if isCacheValid(cacheZipPath) {
-		fmt.Println("Found cached ZIP file, extracting XML...")
-		content, err := extractXmlFromZipFile(cacheZipPath)
+		slog.Debug("extracting XML from cached ZIP file", "path", cacheZipPath)
+		content, err := extractXmlFromZipFile(cacheZipPath, fileName+".xml")
 		if err != nil {
-			fmt.Printf("Failed to extract from cached ZIP: %v, downloading fresh copy...\n", err)
+			slog.Warn("failed to extract from cached ZIP, downloading fresh copy", "error", err)
 		} else {
 			// 保存解压后的XML到缓存
 			if err := os.WriteFile(cacheFilePath, content, 0644); err != nil {
-				fmt.Printf("Warning: failed to save XML cache: %v\n", err)
-			} else {
-				fmt.Println("XML cached successfully.")
+				slog.Warn("failed to save XML cache", "error", err)
 			}
-			return content, nil
+			source.Cached = true
+			source.SHA256 = fileSHA256(cacheZipPath)
+			return content, source, nil
 		}
 	}
 
-	fmt.Println("No cache found, downloading from network...")
+	slog.Info("no cache found, downloading", "url", fileUrl)
 
 	// 从网络获取数据
-	content, err := fetchUcdXmlContent(baseUrl)
+	content, archive, err := fetchUcdXmlContent(ctx, fileUrl, fileName)
 	if err != nil {
-		return nil, err
+		return nil, source, err
 	}
+	source.SHA256 = sha256Hex(archive)
 
-	// 保存XML到缓存
+	// 保存ZIP和XML到缓存
+	if err := os.WriteFile(cacheZipPath, archive, 0644); err != nil {
+		slog.Warn("failed to save ZIP cache", "error", err)
+	}
 	if err := os.WriteFile(cacheFilePath, content, 0644); err != nil {
-		fmt.Printf("Warning: failed to save XML cache: %v\n", err)
 		// 即使缓存保存失败，也返回获取到的内容
-	} else {
-		fmt.Println("XML data cached successfully.")
+		slog.Warn("failed to save XML cache", "error", err)
 	}
 
-	return content, nil
+	return content, source, nil
+}
+
+// fileSHA256 计算文件的 SHA-256，文件不存在时返回空字符串
+func fileSHA256(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return sha256Hex(data)
 }
 
 // isCacheValid 检查缓存文件是否存在
@@ -221,7 +613,7 @@ func isCacheValid(cacheFilePath string) bool {
 }
 
 // extractXmlFromZipFile 从本地ZIP文件中提取XML内容
-func extractXmlFromZipFile(zipFilePath string) ([]byte, error) {
+func extractXmlFromZipFile(zipFilePath, xmlName string) ([]byte, error) {
 	zipReader, err := zip.OpenReader(zipFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open zip file: %w", err)
@@ -229,7 +621,7 @@ func extractXmlFromZipFile(zipFilePath string) ([]byte, error) {
 	defer zipReader.Close()
 
 	for _, file := range zipReader.File {
-		if file.Name != "ucd.all.flat.xml" {
+		if file.Name != xmlName {
 			continue
 		}
 
@@ -247,109 +639,99 @@ func extractXmlFromZipFile(zipFilePath string) ([]byte, error) {
 		return content, nil
 	}
 
-	return nil, fmt.Errorf("ucd.all.flat.xml not found in zip file")
+	return nil, fmt.Errorf("%s not found in zip file", xmlName)
 }
 
-func fetchUcdXmlContent(baseUrl string) ([]byte, error) {
-	fileName := "ucd.all.flat.zip"
-
-	filePath, err := url.JoinPath(baseUrl, fileName)
+// fetchUcdXmlContent 下载ZIP文件，返回其中的XML内容和ZIP原始数据
+func fetchUcdXmlContent(ctx context.Context, fileUrl, fileName string) ([]byte, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct file URL: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := http.Get(filePath)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch file: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch file: %w", err)
 	}
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch file: status code %d", resp.StatusCode)
+		return nil, nil, fmt.Errorf("failed to fetch file: status code %d", resp.StatusCode)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	tracker := reporter.Start(progress.Stage{Name: "download " + fileName, Unit: progress.Bytes, Total: resp.ContentLength})
+	data, err := io.ReadAll(progress.Reader(resp.Body, tracker))
+	tracker.Done()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create zip reader: %w", err)
+		return nil, nil, fmt.Errorf("failed to create zip reader: %w", err)
 	}
 
 	var xmlContent []byte
 	for _, file := range zipReader.File {
-		if file.Name != "ucd.all.flat.xml" {
+		if file.Name != fileName+".xml" {
 			continue
 		}
 
 		rc, err := file.Open()
 		if err != nil {
-			return nil, fmt.Errorf("failed to open file in zip: %w", err)
+			return nil, nil, fmt.Errorf("failed to open file in zip: %w", err)
 		}
 		defer rc.Close()
 
 		xmlContent, err = io.ReadAll(rc)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read XML content: %w", err)
+			return nil, nil, fmt.Errorf("failed to read XML content: %w", err)
 		}
 	}
 
 	if len(xmlContent) == 0 {
-		return nil, fmt.Errorf("ucd.all.flat.xml not found in zip file")
+		return nil, nil, fmt.Errorf("%s.xml not found in zip file", fileName)
 	}
 
-	return xmlContent, nil
+	return xmlContent, data, nil
 }
 
 // analyzeCharacterTypes 分析字符类型统计
-func analyzeCharacterTypes(mongoClient *database.MongoClient) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
+func analyzeCharacterTypes(ctx context.Context, mongoClient *database.MongoClient) error {
 	// 总字符数
-	total, err := mongoClient.CodePoints.CountDocuments(ctx, bson.M{})
+	total, err := mongoClient.Query().Count(ctx)
 	if err != nil {
 		return fmt.Errorf("error counting total: %w", err)
 	}
-	fmt.Printf("总字符数: %d\n", total)
+	fmt.Printf("  Total: %d\n", total)
 
 	// 有名称的字符
-	withNames, err := mongoClient.CodePoints.CountDocuments(ctx, bson.M{
-		"name": bson.M{"$exists": true, "$ne": ""},
-	})
+	withNames, err := mongoClient.Query().HasName().Count(ctx)
 	if err != nil {
 		return fmt.Errorf("error counting with names: %w", err)
 	}
-	fmt.Printf("有名称的字符: %d\n", withNames)
+	fmt.Printf("  With names: %d\n", withNames)
 
 	// 保留字符
-	deprecated, err := mongoClient.CodePoints.CountDocuments(ctx, bson.M{
-		"deprecated": true,
-	})
+	deprecated, err := mongoClient.Query().Property("deprecated", true).Count(ctx)
 	if err != nil {
 		return fmt.Errorf("error counting deprecated: %w", err)
 	}
-	fmt.Printf("保留字符: %d\n", deprecated)
+	fmt.Printf("  Deprecated: %d\n", deprecated)
 
 	// 非字符
-	nonchar, err := mongoClient.CodePoints.CountDocuments(ctx, bson.M{
-		"noncharacter": true,
-	})
+	nonchar, err := mongoClient.Query().Property("noncharacter", true).Count(ctx)
 	if err != nil {
 		return fmt.Errorf("error counting noncharacter: %w", err)
 	}
-	fmt.Printf("非字符: %d\n", nonchar)
+	fmt.Printf("  Noncharacters: %d\n", nonchar)
 
 	// 有CP字段的字符
-	withCP, err := mongoClient.CodePoints.CountDocuments(ctx, bson.M{
-		"cp": bson.M{"$exists": true, "$ne": ""},
-	})
+	withCP, err := mongoClient.Query().SingleCodePoints().Count(ctx)
 	if err != nil {
 		return fmt.Errorf("error counting with CP: %w", err)
 	}
-	fmt.Printf("有CP字段的字符: %d\n", withCP)
+	fmt.Printf("  Single code points: %d\n", withCP)
 
 	return nil
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"udc2mongo/metrics"
+)
+
+// appMetrics 本次运行的指标，在 main 中创建
+var appMetrics = metrics.New()
+
+// serveMetrics 在 addr 上提供 /metrics，命令运行期间可被抓取
+func serveMetrics(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", appMetrics.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server stopped", "addr", addr, "error", err)
+		}
+	}()
+	slog.Info("serving metrics", "addr", addr, "path", "/metrics")
+	return server
+}
+
+// pushMetrics 命令结束后推送到 Pushgateway，按命令分组
+func pushMetrics(url, job, command string) {
+	// 命令的 ctx 可能已取消，推送使用新的 ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := appMetrics.Push(ctx, url, job, map[string]string{"command": command})
+	if err != nil {
+		slog.Warn("failed to push metrics", "url", url, "error", err)
+		return
+	}
+	slog.Debug("pushed metrics", "url", url, "job", job)
+}
@@ -0,0 +1,158 @@
+// Package metrics 导入和查询的 Prometheus 指标
+//
+// 长时间运行的进程通过 Handler 暴露 /metrics；CronJob 这类批处理任务在结束时调用 Push
+// 推送到 Pushgateway。
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"udc2mongo/model"
+	"udc2mongo/progress"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const namespace = "udc2mongo"
+
+// Metrics 一组注册在独立 Registry 上的指标
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	StageDuration     *prometheus.GaugeVec     // 上次导入各阶段的耗时
+	ImportDuration    prometheus.Gauge         // 上次导入的总耗时
+	LastSuccess       *prometheus.GaugeVec     // 各版本上次成功导入的时间
+	DocumentsWritten  *prometheus.CounterVec   // 各集合写入的文档数
+	DownloadBytes     *prometheus.CounterVec   // 各文件下载的字节数
+	ParseErrors       prometheus.Counter       // XML 解析失败次数
+	InvalidCodePoints prometheus.Counter       // 校验失败被跳过的字符点数
+	CallDuration      *prometheus.HistogramVec // MongoClient 各方法的耗时
+	CallErrors        *prometheus.CounterVec   // MongoClient 各方法返回的错误数
+}
+
+// New 创建并注册全部指标
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		StageDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "import_stage_duration_seconds",
+			Help: "Duration of each stage of the last import.",
+		}, []string{"stage"}),
+		ImportDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "import_duration_seconds",
+			Help: "Total duration of the last import.",
+		}),
+		LastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "import_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful import of each UCD version.",
+		}, []string{"version"}),
+		DocumentsWritten: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "documents_written_total",
+			Help: "Documents written, by collection.",
+		}, []string{"collection"}),
+		DownloadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "download_bytes_total",
+			Help: "Bytes downloaded from unicode.org, by file.",
+		}, []string{"file"}),
+		ParseErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "parse_errors_total",
+			Help: "UCD XML documents that failed to parse.",
+		}),
+		InvalidCodePoints: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "invalid_code_points_total",
+			Help: "Code points skipped because they failed validation.",
+		}),
+		CallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "mongo_call_duration_seconds",
+			Help:    "Latency of MongoClient methods.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{"method"}),
+		CallErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "mongo_call_errors_total",
+			Help: "Errors returned by MongoClient methods.",
+		}, []string{"method"}),
+	}
+
+	m.Registry.MustRegister(
+		m.StageDuration, m.ImportDuration, m.LastSuccess,
+		m.DocumentsWritten, m.DownloadBytes, m.ParseErrors, m.InvalidCodePoints,
+		m.CallDuration, m.CallErrors,
+	)
+	return m
+}
+
+// ObserveCall 记录一次 MongoClient 调用，签名与 database.CallObserver 相同
+func (m *Metrics) ObserveCall(method string, elapsed time.Duration, err error) {
+	m.CallDuration.WithLabelValues(method).Observe(elapsed.Seconds())
+	if err != nil {
+		m.CallErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// RecordImport 记录一次导入的阶段耗时和跳过的字符点，err 为 nil 时更新该版本的成功时间
+func (m *Metrics) RecordImport(run *model.ImportRun, err error) {
+	for _, stage := range run.Stages {
+		m.StageDuration.WithLabelValues(stage.Name).Set(float64(stage.DurationMS) / 1000)
+	}
+	m.ImportDuration.Set(time.Since(run.StartedAt).Seconds())
+	m.InvalidCodePoints.Add(float64(run.Counts["skipped"]))
+	if err == nil {
+		m.LastSuccess.WithLabelValues(run.Version).SetToCurrentTime()
+	}
+}
+
+// Progress 包装 next，把下载字节数和写入文档数计入指标
+func (m *Metrics) Progress(next progress.Reporter) progress.Reporter {
+	return &reporter{m: m, next: next}
+}
+
+type reporter struct {
+	m    *Metrics
+	next progress.Reporter
+}
+
+func (r *reporter) Start(stage progress.Stage) progress.Tracker {
+	t := &tracker{next: r.next.Start(stage)}
+	switch stage.Unit {
+	case progress.Bytes:
+		t.counter = r.m.DownloadBytes.WithLabelValues(strings.TrimPrefix(stage.Name, "download "))
+	case progress.Documents:
+		t.counter = r.m.DocumentsWritten.WithLabelValues(strings.TrimPrefix(stage.Name, "write "))
+	}
+	return t
+}
+
+type tracker struct {
+	next    progress.Tracker
+	counter prometheus.Counter
+}
+
+func (t *tracker) Add(n int64) {
+	if t.counter != nil {
+		t.counter.Add(float64(n))
+	}
+	t.next.Add(n)
+}
+
+func (t *tracker) Done() {
+	t.next.Done()
+}
+
+// Handler 返回 /metrics 的 HTTP 处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// Push 将全部指标推送到 Pushgateway，替换 job 和 grouping 相同的旧指标
+func (m *Metrics) Push(ctx context.Context, url, job string, grouping map[string]string) error {
+	pusher := push.New(url, job).Gatherer(m.Registry)
+	for name, value := range grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+	return pusher.PushContext(ctx)
+}
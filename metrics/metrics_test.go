@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"udc2mongo/model"
+	"udc2mongo/progress"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// pushedFamilies 启动一个记录推送内容的 Pushgateway，返回它的地址和收到的指标
+func pushedFamilies(t *testing.T) (string, func() (string, map[string]*dto.MetricFamily)) {
+	t.Helper()
+
+	var (
+		path     string
+		families map[string]*dto.MetricFamily
+		err      error
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "unexpected method "+r.Method, http.StatusMethodNotAllowed)
+			return
+		}
+		path = r.URL.Path
+		families = make(map[string]*dto.MetricFamily)
+		decoder := expfmt.NewDecoder(r.Body, expfmt.ResponseFormat(r.Header))
+		for {
+			mf := &dto.MetricFamily{}
+			if err = decoder.Decode(mf); err != nil {
+				break
+			}
+			families[mf.GetName()] = mf
+		}
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, func() (string, map[string]*dto.MetricFamily) {
+		if err != nil {
+			t.Fatalf("failed to decode pushed metrics: %v", err)
+		}
+		return path, families
+	}
+}
+
+// metricValue 返回带有 label=value 的序列的值
+func metricValue(t *testing.T, families map[string]*dto.MetricFamily, name, label, value string) float64 {
+	t.Helper()
+	mf, ok := families[name]
+	if !ok {
+		t.Fatalf("%s was not pushed", name)
+	}
+	for _, m := range mf.GetMetric() {
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() != label || lp.GetValue() != value {
+				continue
+			}
+			switch {
+			case m.Gauge != nil:
+				return m.GetGauge().GetValue()
+			case m.Counter != nil:
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	t.Fatalf("%s has no series with %s=%q", name, label, value)
+	return 0
+}
+
+func TestPush(t *testing.T) {
+	url, received := pushedFamilies(t)
+	m := New()
+
+	tracker := m.Progress(progress.Discard).Start(progress.Stage{Name: "write code_points", Unit: progress.Documents, Total: 1500})
+	tracker.Add(1000)
+	tracker.Add(500)
+	tracker.Done()
+
+	before := time.Now()
+	run := &model.ImportRun{
+		Version:   "16.0.0",
+		StartedAt: before.Add(-time.Minute),
+		Stages:    []model.ImportStage{{Name: "download", DurationMS: 2500}, {Name: "write", DurationMS: 40000}},
+		Counts:    map[string]int64{"skipped": 3},
+	}
+	m.RecordImport(run, nil)
+
+	if err := m.Push(context.Background(), url, "udc2mongo", map[string]string{"command": "import"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path, families := received()
+	if want := "/metrics/job/udc2mongo/command/import"; path != want {
+		t.Errorf("pushed to %s, want %s", path, want)
+	}
+
+	for stage, want := range map[string]float64{"download": 2.5, "write": 40} {
+		if got := metricValue(t, families, "udc2mongo_import_stage_duration_seconds", "stage", stage); got != want {
+			t.Errorf("stage %s duration %v, want %v", stage, got, want)
+		}
+	}
+	if got := metricValue(t, families, "udc2mongo_documents_written_total", "collection", "code_points"); got != 1500 {
+		t.Errorf("documents written %v, want 1500", got)
+	}
+	got := metricValue(t, families, "udc2mongo_import_last_success_timestamp_seconds", "version", "16.0.0")
+	if got < float64(before.Unix()) || got > float64(time.Now().Unix()+1) {
+		t.Errorf("last success timestamp %v, want about %d", got, before.Unix())
+	}
+}
+
+func TestRecordImportFailure(t *testing.T) {
+	m := New()
+	m.RecordImport(&model.ImportRun{Version: "16.0.0", StartedAt: time.Now()}, errors.New("write failed"))
+
+	families, err := m.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range families {
+		if mf.GetName() == "udc2mongo_import_last_success_timestamp_seconds" && len(mf.GetMetric()) > 0 {
+			t.Errorf("failed import set the last success timestamp")
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PropertyAlias 一个属性值的别名，对应 PropertyValueAliases.txt 的一行
+//
+// ccc 的 Short 为数值，例如 ccc 230 的 Short 为 230，A 在 Others 中。
+//
+// See: https://www.unicode.org/reports/tr44/#PropertyValueAliases.txt
+type PropertyAlias struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+
+	Property     string   `bson:"property" json:"property"`           // 属性短名，例如 gc
+	PropertyName string   `bson:"property_name" json:"property_name"` // 属性全名，例如 General_Category
+	Short        string   `bson:"short" json:"short"`                 // 值的短名，例如 Lu，即 XML 中的取值
+	Long         string   `bson:"long" json:"long"`                   // 值的全名，例如 Uppercase_Letter
+	Others       []string `bson:"others" json:"others,omitempty"`     // 其他别名，例如 gc P 的 punct
+
+	// 时间戳
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// PropertyAliases 属性名和属性值的别名表，查找时按 UAX44-LM3 忽略大小写、空白、下划线和连字符
+type PropertyAliases struct {
+	Values []PropertyAlias
+
+	properties map[string]string         // 属性的各种写法 -> 属性短名
+	index      map[string]map[string]int // 属性短名 -> 值的各种写法 -> Values 下标
+}
+
+// ParsePropertyAliases 解析 PropertyAliases.txt 和 PropertyValueAliases.txt
+//
+// See: https://www.unicode.org/reports/tr44/#PropertyAliases.txt
+func ParsePropertyAliases(properties, values io.Reader) (*PropertyAliases, error) {
+	names := make(map[string]string) // 属性短名 -> 全名
+	var others [][]string            // 属性的其他写法
+	err := eachDataLine(properties, func(fields []string) error {
+		if len(fields) < 2 {
+			return fmt.Errorf("expected at least 2 fields, got %d", len(fields))
+		}
+		names[fields[0]] = fields[1]
+		others = append(others, fields)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PropertyAliases.txt: %w", err)
+	}
+
+	var aliases []PropertyAlias
+	err = eachDataLine(values, func(fields []string) error {
+		if len(fields) < 3 {
+			return fmt.Errorf("expected at least 3 fields, got %d", len(fields))
+		}
+		alias := PropertyAlias{
+			Property:     fields[0],
+			PropertyName: names[fields[0]],
+			Short:        fields[1],
+			Long:         fields[2],
+			Others:       fields[3:],
+		}
+		// ccc 的格式为 ccc; 230; A; Above
+		if alias.Property == "ccc" && len(fields) >= 4 {
+			alias.Long = fields[3]
+			alias.Others = append([]string{fields[2]}, fields[4:]...)
+		}
+		if alias.PropertyName == "" {
+			alias.PropertyName = alias.Property
+		}
+		aliases = append(aliases, alias)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PropertyValueAliases.txt: %w", err)
+	}
+
+	a := NewPropertyAliases(aliases)
+	for _, fields := range others {
+		for _, name := range fields {
+			a.properties[looseKey(name)] = fields[0]
+		}
+	}
+	return a, nil
+}
+
+// eachDataLine 按分号拆分非注释、非空行，忽略 # @missing 等注释
+func eachDataLine(r io.Reader, fn func(fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if err := fn(fields); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// NewPropertyAliases 由属性值别名建立查找表，用于从数据库读出的 property_aliases
+func NewPropertyAliases(values []PropertyAlias) *PropertyAliases {
+	a := &PropertyAliases{
+		Values:     values,
+		properties: make(map[string]string),
+		index:      make(map[string]map[string]int),
+	}
+	for i, v := range values {
+		a.properties[looseKey(v.Property)] = v.Property
+		a.properties[looseKey(v.PropertyName)] = v.Property
+
+		byValue := a.index[v.Property]
+		if byValue == nil {
+			byValue = make(map[string]int)
+			a.index[v.Property] = byValue
+		}
+		for _, name := range append([]string{v.Short, v.Long}, v.Others...) {
+			if _, ok := byValue[looseKey(name)]; !ok {
+				byValue[looseKey(name)] = i
+			}
+		}
+	}
+	return a
+}
+
+// Property 返回属性的短名，property 可以是短名、全名或其他别名
+func (a *PropertyAliases) Property(property string) (string, bool) {
+	short, ok := a.properties[looseKey(property)]
+	return short, ok
+}
+
+// Lookup 查找属性值，property 和 value 都可以是任意一种写法
+func (a *PropertyAliases) Lookup(property, value string) (*PropertyAlias, bool) {
+	short, ok := a.Property(property)
+	if !ok {
+		return nil, false
+	}
+	i, ok := a.index[short][looseKey(value)]
+	if !ok {
+		return nil, false
+	}
+	return &a.Values[i], true
+}
+
+// Short 返回属性值的短名，找不到时原样返回 value
+func (a *PropertyAliases) Short(property, value string) string {
+	if alias, ok := a.Lookup(property, value); ok {
+		return alias.Short
+	}
+	return value
+}
+
+// Long 返回属性值的全名，找不到时返回空字符串
+func (a *PropertyAliases) Long(property, value string) string {
+	if alias, ok := a.Lookup(property, value); ok {
+		return alias.Long
+	}
+	return ""
+}
+
+// ShortValues 返回属性全部取值的短名，按别名表中的顺序
+func (a *PropertyAliases) ShortValues(property string) []string {
+	short, ok := a.Property(property)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, v := range a.Values {
+		if v.Property == short {
+			values = append(values, v.Short)
+		}
+	}
+	return values
+}
+
+// AssignNames 为字符点填写 gc、bc、sc、lb 和 ea 的全名
+func (a *PropertyAliases) AssignNames(codePoints []CodePoint) {
+	for i := range codePoints {
+		p := &codePoints[i].CodePointProperties
+		fields := []struct {
+			property string
+			value    string
+			name     *string
+		}{
+			{"gc", p.GeneralCategory, &p.GeneralCategoryName},
+			{"bc", p.BidiClass, &p.BidiClassName},
+			{"sc", p.Script, &p.ScriptName},
+			{"lb", p.LineBreak, &p.LineBreakName},
+			{"ea", p.EastAsianWidth, &p.EastAsianWidthName},
+		}
+		for _, f := range fields {
+			if f.value != "" {
+				*f.name = a.Long(f.property, f.value)
+			}
+		}
+	}
+}
+
+// looseKey UAX44-LM3 宽松匹配使用的键
+//
+// See: https://www.unicode.org/reports/tr44/#UAX44-LM3
+func looseKey(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case ' ', '\t', '_', '-':
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
@@ -0,0 +1,57 @@
+package model
+
+// 不属于任何块的字符点的 blk 别名和全名
+const (
+	NoBlockAlias = "NB"
+	NoBlockName  = "No_Block"
+)
+
+// AssignBlocks 按 PropertyValueAliases.txt 中 blk 的行填写 CodePoint.BlockName 和 Block.Alias
+//
+// XML 中的 blk 是别名（例如 ASCII），<blocks> 中是全名（例如 Basic Latin），别名表中的全名按 UAX44-LM3 与块名匹配。
+// 别名表中没有的别名，或全名不在 <blocks> 中，记为 UnknownBlock；
+// 字符点不在对应块的范围内记为 BlockMismatch，这只是一致性检查，不改变对应关系。
+func AssignBlocks(codePoints []CodePoint, blocks []Block, aliases *PropertyAliases) ValidationErrors {
+	byName := make(map[string]int, len(blocks))
+	for i := range blocks {
+		byName[looseKey(blocks[i].Name)] = i
+		if alias, ok := aliases.Lookup("blk", blocks[i].Name); ok {
+			blocks[i].Alias = alias.Short
+		}
+	}
+
+	var errs ValidationErrors
+	for i := range codePoints {
+		cp := &codePoints[i]
+		switch cp.Block {
+		case "":
+			continue
+		case NoBlockAlias:
+			cp.BlockName = NoBlockName
+			continue
+		}
+
+		alias, ok := aliases.Lookup("blk", cp.Block)
+		if !ok {
+			errs = append(errs, &ValidationError{Kind: UnknownBlock, CP: codePointLabel(cp),
+				Detail: "blk " + cp.Block + " is not in PropertyValueAliases.txt"})
+			continue
+		}
+		b, ok := byName[looseKey(alias.Long)]
+		if !ok {
+			errs = append(errs, &ValidationError{Kind: UnknownBlock, CP: codePointLabel(cp),
+				Detail: "blk " + cp.Block + " (" + alias.Long + ") does not match any block"})
+			continue
+		}
+		cp.BlockName = blocks[b].Name
+
+		first, last, ok := codePointSpan(cp)
+		blockFirst, err1 := ParseCodePoint(blocks[b].FirstCP)
+		blockLast, err2 := ParseCodePoint(blocks[b].LastCP)
+		if ok && err1 == nil && err2 == nil && (first < blockFirst || last > blockLast) {
+			errs = append(errs, &ValidationError{Kind: BlockMismatch, CP: codePointLabel(cp),
+				Detail: "outside block " + blocks[b].Name + " (" + blocks[b].FirstCP + ".." + blocks[b].LastCP + ")"})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ParseCodePoint 解析十六进制字符点
+func ParseCodePoint(s string) (rune, error) {
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid code point %q: %w", s, err)
+	}
+	return rune(n), nil
+}
+
+// FormatCodePoint 将字符点格式化为 UCD 使用的十六进制形式
+func FormatCodePoint(r rune) string {
+	return fmt.Sprintf("%04X", r)
+}
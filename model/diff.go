@@ -0,0 +1,297 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChangeKind 变更类型
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// ChangeTarget 变更对象
+type ChangeTarget string
+
+const (
+	TargetCodePoint ChangeTarget = "code_point"
+	TargetBlock     ChangeTarget = "block"
+)
+
+// Change 两个UCD版本之间的单个属性变更
+type Change struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+
+	FromVersion string       `bson:"from_version" json:"from_version"`
+	ToVersion   string       `bson:"to_version" json:"to_version"`
+	Target      ChangeTarget `bson:"target" json:"target"`
+	Kind        ChangeKind   `bson:"kind" json:"kind"`
+	Key         string       `bson:"key" json:"key"` // 字符点或块名称
+	Property    string       `bson:"property,omitempty" json:"property,omitempty"`
+	OldValue    string       `bson:"old_value,omitempty" json:"old_value,omitempty"`
+	NewValue    string       `bson:"new_value,omitempty" json:"new_value,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// ChangeSet 两个UCD版本之间的全部变更
+type ChangeSet struct {
+	FromVersion string   `json:"from_version"`
+	ToVersion   string   `json:"to_version"`
+	Changes     []Change `json:"changes"`
+}
+
+// DiffUCD 比较两个解析后的UCD
+func DiffUCD(from, to *UCD) (*ChangeSet, error) {
+	changes, err := DiffCodePoints(ExtractAllCodePoints(from), ExtractAllCodePoints(to))
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, DiffBlocks(ExtractBlocks(from), ExtractBlocks(to))...)
+
+	return NewChangeSet(from.Version, to.Version, changes), nil
+}
+
+// NewChangeSet 创建变更集并填充版本信息
+func NewChangeSet(fromVersion, toVersion string, changes []Change) *ChangeSet {
+	for i := range changes {
+		changes[i].FromVersion = fromVersion
+		changes[i].ToVersion = toVersion
+	}
+	return &ChangeSet{
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     changes,
+	}
+}
+
+// DiffCodePoints 按字符点逐属性比较
+//
+// 范围条目会按字符点展开；gc 为 Cn 的字符点视为未分配，
+// 未分配变为已分配记为 added，反之记为 removed。
+func DiffCodePoints(from, to []CodePoint) ([]Change, error) {
+	fromIndex, err := indexCodePoints(from)
+	if err != nil {
+		return nil, err
+	}
+	toIndex, err := indexCodePoints(to)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]rune, 0, len(toIndex))
+	for r := range fromIndex {
+		keys = append(keys, r)
+	}
+	for r := range toIndex {
+		if _, ok := fromIndex[r]; !ok {
+			keys = append(keys, r)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	// 范围内的字符点共享同一对条目，缓存比较结果
+	type pair struct{ from, to *CodePoint }
+	cache := make(map[pair][]Change)
+
+	var changes []Change
+	for _, r := range keys {
+		oldCP, newCP := fromIndex[r], toIndex[r]
+		key := FormatCodePoint(r)
+
+		switch {
+		case !isAssigned(oldCP) && !isAssigned(newCP):
+			continue
+		case !isAssigned(oldCP):
+			changes = append(changes, Change{Target: TargetCodePoint, Kind: ChangeAdded, Key: key})
+			continue
+		case !isAssigned(newCP):
+			changes = append(changes, Change{Target: TargetCodePoint, Kind: ChangeRemoved, Key: key})
+			continue
+		}
+
+		p := pair{oldCP, newCP}
+		diff, ok := cache[p]
+		if !ok {
+			diff = diffProperties(&oldCP.CodePointProperties, &newCP.CodePointProperties)
+			cache[p] = diff
+		}
+		for _, c := range diff {
+			c.Key = key
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+// DiffBlocks 按块名称比较块范围
+func DiffBlocks(from, to []Block) []Change {
+	fromIndex := make(map[string]Block, len(from))
+	for _, b := range from {
+		fromIndex[b.Name] = b
+	}
+
+	var changes []Change
+	for _, b := range to {
+		old, ok := fromIndex[b.Name]
+		if !ok {
+			changes = append(changes, Change{Target: TargetBlock, Kind: ChangeAdded, Key: b.Name})
+			continue
+		}
+		delete(fromIndex, b.Name)
+
+		if old.FirstCP != b.FirstCP {
+			changes = append(changes, Change{
+				Target: TargetBlock, Kind: ChangeChanged, Key: b.Name,
+				Property: "first_cp", OldValue: old.FirstCP, NewValue: b.FirstCP,
+			})
+		}
+		if old.LastCP != b.LastCP {
+			changes = append(changes, Change{
+				Target: TargetBlock, Kind: ChangeChanged, Key: b.Name,
+				Property: "last_cp", OldValue: old.LastCP, NewValue: b.LastCP,
+			})
+		}
+	}
+
+	for _, b := range from {
+		if _, ok := fromIndex[b.Name]; ok {
+			changes = append(changes, Change{Target: TargetBlock, Kind: ChangeRemoved, Key: b.Name})
+		}
+	}
+
+	return changes
+}
+
+// indexCodePoints 建立字符点到条目的索引，范围条目会被展开
+func indexCodePoints(codePoints []CodePoint) (map[rune]*CodePoint, error) {
+	index := make(map[rune]*CodePoint, len(codePoints))
+	for i := range codePoints {
+		cp := &codePoints[i]
+		if cp.CP != "" {
+			r, err := ParseCodePoint(cp.CP)
+			if err != nil {
+				return nil, err
+			}
+			index[r] = cp
+			continue
+		}
+
+		first, err := ParseCodePoint(cp.FirstCP)
+		if err != nil {
+			return nil, err
+		}
+		last, err := ParseCodePoint(cp.LastCP)
+		if err != nil {
+			return nil, err
+		}
+		for r := first; r <= last; r++ {
+			index[r] = cp
+		}
+	}
+	return index, nil
+}
+
+// isAssigned 检查字符点是否已分配
+func isAssigned(cp *CodePoint) bool {
+	return cp != nil && cp.GeneralCategory != "" && cp.GeneralCategory != "Cn"
+}
+
+// diffProperties 逐属性比较，属性名使用 bson 标签
+func diffProperties(from, to *CodePointProperties) []Change {
+	var changes []Change
+	oldValues := PropertyValues(from)
+	newValues := PropertyValues(to)
+	for i := range oldValues {
+		if oldValues[i].Derived || oldValues[i].Value == newValues[i].Value {
+			continue
+		}
+		changes = append(changes, Change{
+			Target:   TargetCodePoint,
+			Kind:     ChangeChanged,
+			Property: oldValues[i].Name,
+			OldValue: oldValues[i].Value,
+			NewValue: newValues[i].Value,
+		})
+	}
+	return changes
+}
+
+// PropertyValue 属性名称（bson 标签）及其字符串形式的值
+//
+// Derived 表示字段不在 XML 中（xml 标签为 -），例如 block_name 和 general_category_name，
+// 由别名表派生，没有加载别名表时为空，比较时跳过，避免两边加载方式不同时产生虚假的变更。
+type PropertyValue struct {
+	Name    string
+	Value   string
+	Derived bool
+}
+
+// PropertyValues 按字段顺序列出全部属性，不包含时间戳
+func PropertyValues(p *CodePointProperties) []PropertyValue {
+	var values []PropertyValue
+	collectPropertyValues(reflect.ValueOf(p).Elem(), &values)
+	return values
+}
+
+var ucdBoolType = reflect.TypeOf(UCDBool(false))
+
+// collectPropertyValues 递归展开 inline 结构体
+func collectPropertyValues(v reflect.Value, values *[]PropertyValue) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("bson"), ",")
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectPropertyValues(v.Field(i), values)
+			continue
+		}
+		if name == "" || name == "-" || name == "created_at" || name == "updated_at" {
+			continue
+		}
+
+		*values = append(*values, PropertyValue{
+			Name:    name,
+			Value:   formatValue(v.Field(i)),
+			Derived: field.Tag.Get("xml") == "-",
+		})
+	}
+}
+
+// formatValue 将属性值格式化为字符串
+func formatValue(v reflect.Value) string {
+	if v.Type() == ucdBoolType {
+		if v.Bool() {
+			return "Y"
+		}
+		return "N"
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Slice:
+		if aliases, ok := v.Interface().([]NameAlias); ok {
+			parts := make([]string, len(aliases))
+			for i, a := range aliases {
+				parts[i] = a.Alias + " (" + a.Type + ")"
+			}
+			return strings.Join(parts, "; ")
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
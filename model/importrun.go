@@ -0,0 +1,109 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImportStatus 导入状态
+type ImportStatus string
+
+const (
+	ImportStarted   ImportStatus = "started"
+	ImportCommitted ImportStatus = "committed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportSource 导入使用的源文件
+type ImportSource struct {
+	File   string `bson:"file" json:"file"`
+	URL    string `bson:"url" json:"url"`
+	SHA256 string `bson:"archive_sha256,omitempty" json:"archive_sha256,omitempty"` // 只有XML缓存、没有ZIP缓存时为空
+	Cached bool   `bson:"cached" json:"cached"`
+}
+
+// ImportStage 单个阶段的耗时，同名阶段（例如 combined 的两次下载）会累加
+type ImportStage struct {
+	Name       string `bson:"name" json:"name"`
+	DurationMS int64  `bson:"duration_ms" json:"duration_ms"`
+}
+
+// ImportRun 一次导入的记录，保存在 import_runs 集合中
+type ImportRun struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+
+	Version string       `bson:"version" json:"version"`
+	Variant Variant      `bson:"variant" json:"variant"`
+	Mode    string       `bson:"mode" json:"mode"` // 实际使用的写入方式，见 database.ImportMode
+	Status  ImportStatus `bson:"status" json:"status"`
+	Error   string       `bson:"error,omitempty" json:"error,omitempty"`
+
+	Sources  []ImportSource   `bson:"sources" json:"sources"`
+	Stages   []ImportStage    `bson:"stages" json:"stages"`
+	Counts   map[string]int64 `bson:"counts" json:"counts"`
+	Warnings ImportWarnings   `bson:"warnings" json:"warnings"`
+
+	StartedAt  time.Time `bson:"started_at" json:"started_at"`
+	FinishedAt time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+// MaxImportWarnings ImportRun 中保留的警告条数
+//
+// 数据有问题时每个字符点都可能产生警告，完整的列表会超过 BSON 文档 16MB 的上限。
+const MaxImportWarnings = 100
+
+// ImportWarnings 校验警告的摘要：按类型计数，只保留前 MaxImportWarnings 条描述
+type ImportWarnings struct {
+	Counts    map[string]int `bson:"counts" json:"counts"`       // 按 ValidationKind 统计的数量
+	Messages  []string       `bson:"messages" json:"messages"`   // 前 MaxImportWarnings 条，见 ValidationReport.Messages
+	Truncated bool           `bson:"truncated" json:"truncated"` // 是否有未保留的描述
+}
+
+// SummarizeWarnings 生成校验结果的警告摘要
+func SummarizeWarnings(report *ValidationReport) ImportWarnings {
+	messages := report.Messages()
+	w := ImportWarnings{
+		Counts:    make(map[string]int),
+		Messages:  messages[:min(len(messages), MaxImportWarnings)],
+		Truncated: len(messages) > MaxImportWarnings,
+	}
+	for _, e := range report.Errors {
+		w.Counts[string(e.Kind)]++
+	}
+	return w
+}
+
+// Stage 记录从 start 到现在的耗时
+func (r *ImportRun) Stage(name string, start time.Time) {
+	ms := time.Since(start).Milliseconds()
+	for i := range r.Stages {
+		if r.Stages[i].Name == name {
+			r.Stages[i].DurationMS += ms
+			return
+		}
+	}
+	r.Stages = append(r.Stages, ImportStage{Name: name, DurationMS: ms})
+}
+
+// Duration 总耗时，未结束时返回 0
+func (r *ImportRun) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// CountRepertoire 按类型统计 repertoire 中的条目数，范围条目记为一条
+func CountRepertoire(r *Repertoire) map[string]int64 {
+	counts := make(map[string]int64)
+	if r == nil {
+		return counts
+	}
+
+	counts["char"] = int64(len(r.CodePoints))
+	counts["reserved"] = int64(len(r.Reserved))
+	counts["noncharacter"] = int64(len(r.Noncharacter))
+	counts["surrogate"] = int64(len(r.Surrogate))
+	return counts
+}
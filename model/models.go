@@ -18,6 +18,7 @@ type UCD struct {
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
 	Version   string    `bson:"version" json:"version"`
+	Variant   Variant   `bson:"variant" json:"variant"`
 }
 
 // Blocks 字符块定义
@@ -31,6 +32,7 @@ type Block struct {
 	FirstCP string             `xml:"first-cp,attr" bson:"first_cp" json:"first_cp"`
 	LastCP  string             `xml:"last-cp,attr" bson:"last_cp" json:"last_cp"`
 	Name    string             `xml:"name,attr" bson:"name" json:"name"`
+	Alias   string             `xml:"-" bson:"alias" json:"alias,omitempty"` // blk 别名，由 AssignBlocks 填写
 
 	// 时间戳
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
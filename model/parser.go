@@ -1,29 +1,122 @@
 package model
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"strings"
+
+	"udc2mongo/progress"
 )
 
-// ParseUCDXML 解析UCD XML数据
-func ParseUCDXML(xmlData []byte) (*UCD, error) {
-	var ucd UCD
+// repertoireElements repertoire 中的子元素，解析进度按这些元素计数
+var repertoireElements = []string{"char", "reserved", "noncharacter", "surrogate"}
+
+// ParseUCDXML 解析UCD XML数据，reporter 为 nil 时不报告进度
+func ParseUCDXML(xmlData []byte, reporter progress.Reporter) (*UCD, error) {
+	if reporter == nil {
+		reporter = progress.Discard
+	}
+
+	// 预先数出元素总数，解析进度才能估计剩余时间
+	var total int64
+	for _, name := range repertoireElements {
+		total += int64(bytes.Count(xmlData, []byte("<"+name+" ")))
+	}
+	tracker := reporter.Start(progress.Stage{Name: "parse", Unit: progress.Elements, Total: total})
+	defer tracker.Done()
 
 	// 解析XML
-	err := xml.Unmarshal(xmlData, &ucd)
+	ucd, err := decodeUCD(xml.NewDecoder(bytes.NewReader(xmlData)), tracker)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse XML: %w", err)
 	}
 
-	fmt.Printf("Parsed UCD with %d repertoire items\n",
-		len(getCodePointsFromRepertoire(ucd.Repertoire)))
+	return ucd, nil
+}
+
+// decodeUCD 逐个解码根元素的子元素，结果与 xml.Unmarshal 相同
+func decodeUCD(decoder *xml.Decoder, tracker progress.Tracker) (*UCD, error) {
+	var ucd UCD
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		root, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
 
-	if ucd.Blocks != nil {
-		fmt.Printf("Found %d blocks\n", len(ucd.Blocks.Blocks))
+		for _, attr := range root.Attr {
+			if attr.Name.Local == "xmlns" {
+				ucd.Xmlns = attr.Value
+			}
+		}
+		err = eachChild(decoder, func(child xml.StartElement) error {
+			switch child.Name.Local {
+			case "description":
+				return decoder.DecodeElement(&ucd.Description, &child)
+			case "repertoire":
+				ucd.Repertoire = &Repertoire{}
+				return decodeRepertoire(decoder, ucd.Repertoire, tracker)
+			case "blocks":
+				ucd.Blocks = &Blocks{}
+				return decoder.DecodeElement(ucd.Blocks, &child)
+			default:
+				return decoder.Skip()
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &ucd, nil
 	}
+}
 
-	return &ucd, nil
+// decodeRepertoire 解码 repertoire 的子元素，每个元素计入一次进度
+func decodeRepertoire(decoder *xml.Decoder, r *Repertoire, tracker progress.Tracker) error {
+	return eachChild(decoder, func(child xml.StartElement) error {
+		var list *[]CodePoint
+		switch child.Name.Local {
+		case "char":
+			list = &r.CodePoints
+		case "reserved":
+			list = &r.Reserved
+		case "noncharacter":
+			list = &r.Noncharacter
+		case "surrogate":
+			list = &r.Surrogate
+		default:
+			return decoder.Skip()
+		}
+
+		var cp CodePoint
+		if err := decoder.DecodeElement(&cp, &child); err != nil {
+			return err
+		}
+		*list = append(*list, cp)
+		tracker.Add(1)
+		return nil
+	})
+}
+
+// eachChild 对当前元素的每个子元素调用 fn，fn 必须读完该子元素，读到结束标签时返回
+func eachChild(decoder *xml.Decoder, fn func(xml.StartElement) error) error {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if err := fn(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
 }
 
 // getCodePointsFromRepertoire 从 repertoire 中提取所有字符点
@@ -35,25 +128,21 @@ func getCodePointsFromRepertoire(repertoire *Repertoire) []CodePoint {
 	var allCodePoints []CodePoint
 
 	// 添加普通字符
-	fmt.Printf("  - Regular characters: %d\n", len(repertoire.CodePoints))
 	allCodePoints = append(allCodePoints, repertoire.CodePoints...)
 
 	// 添加保留字符
-	fmt.Printf("  - Reserved characters: %d\n", len(repertoire.Reserved))
 	for _, cp := range repertoire.Reserved {
 		cp.Deprecated = true // 标记为保留
 		allCodePoints = append(allCodePoints, cp)
 	}
 
 	// 添加非字符
-	fmt.Printf("  - Noncharacters: %d\n", len(repertoire.Noncharacter))
 	for _, cp := range repertoire.Noncharacter {
 		cp.Noncharacter = true
 		allCodePoints = append(allCodePoints, cp)
 	}
 
 	// 添加代理对
-	fmt.Printf("  - Surrogate characters: %d\n", len(repertoire.Surrogate))
 	allCodePoints = append(allCodePoints, repertoire.Surrogate...)
 
 	return allCodePoints
@@ -68,7 +157,6 @@ func ExtractAllCodePoints(ucd *UCD) []CodePoint {
 		allCodePoints = append(allCodePoints, getCodePointsFromRepertoire(ucd.Repertoire)...)
 	}
 
-	fmt.Printf("Extracted %d total code points\n", len(allCodePoints))
 	return allCodePoints
 }
 
@@ -81,20 +169,6 @@ func ExtractBlocks(ucd *UCD) []Block {
 	return ucd.Blocks.Blocks
 }
 
-// ValidateCodePoint 验证字符点数据
-func ValidateCodePoint(cp *CodePoint) error {
-	if cp.CP == "" && cp.FirstCP == "" {
-		return fmt.Errorf("code point must have either cp or first-cp")
-	}
-
-	// 如果有范围，检查last-cp
-	if cp.FirstCP != "" && cp.LastCP == "" {
-		return fmt.Errorf("code point with first-cp must also have last-cp")
-	}
-
-	return nil
-}
-
 // NormalizeCodePoint 标准化字符点数据
 func NormalizeCodePoint(cp *CodePoint) {
 	// 规范化字符串字段，移除多余空格
@@ -136,19 +210,25 @@ func NormalizeCodePoint(cp *CodePoint) {
 	}
 }
 
-// ProcessUCDForMongoDB 处理UCD数据准备保存到MongoDB
-func ProcessUCDForMongoDB(ucd *UCD) ([]CodePoint, []Block, error) {
+// ProcessUCDForMongoDB 处理UCD数据准备保存到MongoDB，同时返回校验结果
+//
+// 有结构错误的条目被跳过，其余错误只记录在报告中。ucd.Variant 为 unihan 时不检查 0..10FFFF 的覆盖。
+// 块的别名和全名需要属性值别名表，由 AssignBlocks 另外填写。
+func ProcessUCDForMongoDB(ucd *UCD) ([]CodePoint, []Block, *ValidationReport, error) {
 	// 提取所有字符点
 	codePoints := ExtractAllCodePoints(ucd)
 
 	// 验证和标准化字符点
+	report := &ValidationReport{}
 	validCodePoints := make([]CodePoint, 0, len(codePoints))
 	for i := range codePoints {
 		cp := &codePoints[i]
 
 		// 验证
-		if err := ValidateCodePoint(cp); err != nil {
-			fmt.Printf("Warning: skipping invalid code point: %v\n", err)
+		errs := validateCodePoint(cp)
+		report.Errors = append(report.Errors, errs...)
+		if errs.Skip() {
+			report.Skipped++
 			continue
 		}
 
@@ -161,8 +241,6 @@ func ProcessUCDForMongoDB(ucd *UCD) ([]CodePoint, []Block, error) {
 	// 提取块
 	blocks := ExtractBlocks(ucd)
 
-	fmt.Printf("Processed %d valid code points and %d blocks\n",
-		len(validCodePoints), len(blocks))
-
-	return validCodePoints, blocks, nil
+	report.Errors = append(report.Errors, ValidateRepertoire(ucd.Repertoire, blocks, ucd.Variant != VariantUnihan)...)
+	return validCodePoints, blocks, report, nil
 }
@@ -35,16 +35,20 @@ type CodePoint struct {
 type CodePointProperties struct {
 	AgeProperties           `bson:",inline"` // See: https://unicode.org/reports/tr42/#d1e3048
 	NameProperties          `bson:",inline"` // See: https://unicode.org/reports/tr42/#d1e3071
-	NameAliases             []NameAlias      `xml:"name-alias" bson:"name_aliases" json:"name_aliases,omitempty"`      // See: https://unicode.org/reports/tr42/#d1e3145
-	Block                   string           `xml:"blk,attr" bson:"block" json:"block,omitempty"`                      // See: https://unicode.org/reports/tr42/#d1e3168
-	GeneralCategory         string           `xml:"gc,attr" bson:"general_category" json:"general_category,omitempty"` // See: https://unicode.org/reports/tr42/#d1e3191
-	CombiningClass          int              `xml:"ccc,attr" bson:"combining_class" json:"combining_class,omitempty"`  // See: https://unicode.org/reports/tr42/#d1e3215
+	NameAliases             []NameAlias      `xml:"name-alias" bson:"name_aliases" json:"name_aliases,omitempty"`                    // See: https://unicode.org/reports/tr42/#d1e3145
+	Block                   string           `xml:"blk,attr" bson:"block" json:"block,omitempty"`                                    // See: https://unicode.org/reports/tr42/#d1e3168
+	BlockName               string           `xml:"-" bson:"block_name" json:"block_name,omitempty"`                                 // 块全名，由 AssignBlocks 填写
+	GeneralCategory         string           `xml:"gc,attr" bson:"general_category" json:"general_category,omitempty"`               // See: https://unicode.org/reports/tr42/#d1e3191
+	GeneralCategoryName     string           `xml:"-" bson:"general_category_name,omitempty" json:"general_category_name,omitempty"` // gc 全名，由 PropertyAliases.AssignNames 填写
+	CombiningClass          int              `xml:"ccc,attr" bson:"combining_class" json:"combining_class,omitempty"`                // See: https://unicode.org/reports/tr42/#d1e3215
 	BidiProperties          `bson:",inline"` // See: https://unicode.org/reports/tr42/#d1e3241
 	DecompositionProperties `bson:",inline"` // See: https://unicode.org/reports/tr42/#d1e3332
 	NumericProperties       `bson:",inline"` // See: https://unicode.org/reports/tr42/#d1e3393
 	JoiningProperties       `bson:",inline"` // See: https://unicode.org/reports/tr42/#d1e3422
-	LineBreak               string           `xml:"lb,attr" bson:"line_break" json:"line_break,omitempty"`             // See: https://unicode.org/reports/tr42/#d1e3467
-	EastAsianWidth          string           `xml:"ea,attr" bson:"east_asian_width" json:"east_asian_width,omitempty"` // See: https://unicode.org/reports/tr42/#d1e3491
+	LineBreak               string           `xml:"lb,attr" bson:"line_break" json:"line_break,omitempty"`                           // See: https://unicode.org/reports/tr42/#d1e3467
+	LineBreakName           string           `xml:"-" bson:"line_break_name,omitempty" json:"line_break_name,omitempty"`             // lb 全名
+	EastAsianWidth          string           `xml:"ea,attr" bson:"east_asian_width" json:"east_asian_width,omitempty"`               // See: https://unicode.org/reports/tr42/#d1e3491
+	EastAsianWidthName      string           `xml:"-" bson:"east_asian_width_name,omitempty" json:"east_asian_width_name,omitempty"` // ea 全名
 	CaseProperties          `bson:",inline"` // See: https://unicode.org/reports/tr42/#d1e3514
 	ScriptProperties        `bson:",inline"` // See: https://unicode.org/reports/tr42/#d1e3614
 
@@ -84,6 +88,7 @@ type CodePointProperties struct {
 	// 变音符号属性
 	Diacritic                  UCDBool `xml:"Dia,attr" bson:"diacritic" json:"diacritic,omitempty"`
 	Extender                   UCDBool `xml:"Ext,attr" bson:"extender" json:"extender,omitempty"`
+	SoftDotted                 UCDBool `xml:"SD,attr" bson:"soft_dotted" json:"soft_dotted,omitempty"`
 	PrependedConcatenationMark UCDBool `xml:"PCM,attr" bson:"prepended_concatenation_mark" json:"prepended_concatenation_mark,omitempty"`
 
 	// 字符属性
@@ -172,6 +177,7 @@ type NameAlias struct {
 // See: https://unicode.org/reports/tr42/#d1e3241
 type BidiProperties struct {
 	BidiClass             string  `xml:"bc,attr" bson:"bidi_class" json:"bidi_class,omitempty"`                              // See: https://unicode.org/reports/tr42/#lp:d1e3148
+	BidiClassName         string  `xml:"-" bson:"bidi_class_name,omitempty" json:"bidi_class_name,omitempty"`                // bc 全名
 	BidiMirrored          UCDBool `xml:"Bidi_M,attr" bson:"bidi_mirrored" json:"bidi_mirrored,omitempty"`                    // See: https://unicode.org/reports/tr42/#lp:d1e3157
 	BidiMirroringGlyph    string  `xml:"bmg,attr" bson:"bidi_mirroring_glyph" json:"bidi_mirroring_glyph,omitempty"`         // See: https://unicode.org/reports/tr42/#lp:d1e3167
 	BidiControl           UCDBool `xml:"Bidi_C,attr" bson:"bidi_control" json:"bidi_control,omitempty"`                      // See: https://unicode.org/reports/tr42/#lp:d1e3179
@@ -248,5 +254,6 @@ type CaseProperties struct {
 // See: https://unicode.org/reports/tr42/#d1e3614
 type ScriptProperties struct {
 	Script           string `xml:"sc,attr" bson:"script" json:"script,omitempty"`
+	ScriptName       string `xml:"-" bson:"script_name,omitempty" json:"script_name,omitempty"` // sc 全名
 	ScriptExtensions string `xml:"scx,attr" bson:"script_extensions" json:"script_extensions,omitempty"`
 }
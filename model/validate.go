@@ -0,0 +1,342 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// MaxCodePoint 最大的字符点
+const MaxCodePoint = 0x10FFFF
+
+// ValidationKind 校验错误的类型
+type ValidationKind string
+
+const (
+	// 单个条目的结构错误，条目会被跳过
+	MissingCodePoint     ValidationKind = "missing_code_point"     // 没有 cp 也没有 first-cp
+	ConflictingCodePoint ValidationKind = "conflicting_code_point" // 同时有 cp 和 first-cp
+	MissingLastCP        ValidationKind = "missing_last_cp"        // 有 first-cp 没有 last-cp
+	InvalidHex           ValidationKind = "invalid_hex"            // 不是 4 到 6 位大写十六进制
+	OutOfRange           ValidationKind = "out_of_range"           // 大于 10FFFF
+	ReversedRange        ValidationKind = "reversed_range"         // first-cp 大于 last-cp
+
+	// 单个条目的属性值错误，条目保留
+	UnknownGeneralCategory ValidationKind = "unknown_general_category"
+	UnknownBidiClass       ValidationKind = "unknown_bidi_class"
+	UnknownScript          ValidationKind = "unknown_script"
+
+	// 整个 repertoire 的错误
+	DuplicateCodePoint ValidationKind = "duplicate_code_point" // 同一个字符点出现多次
+	OverlappingRange   ValidationKind = "overlapping_range"    // 范围与前面的条目重叠
+	CoverageGap        ValidationKind = "coverage_gap"         // 0..10FFFF 中没有条目的字符点
+	OutsideBlock       ValidationKind = "outside_block"        // 字符不在任何块中
+	UnknownBlock       ValidationKind = "unknown_block"        // blk 别名找不到对应的块
+	BlockMismatch      ValidationKind = "block_mismatch"       // 字符点不在 blk 对应块的范围内
+)
+
+// skipped 结构错误，出现时条目无法使用
+var skipped = map[ValidationKind]bool{
+	MissingCodePoint:     true,
+	ConflictingCodePoint: true,
+	MissingLastCP:        true,
+	InvalidHex:           true,
+	OutOfRange:           true,
+	ReversedRange:        true,
+}
+
+// ValidationError 单个校验错误
+type ValidationError struct {
+	Kind   ValidationKind `bson:"kind" json:"kind"`
+	CP     string         `bson:"cp" json:"cp"` // 出错的条目或范围，例如 0041 或 4E00..9FFF
+	Detail string         `bson:"detail" json:"detail"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.CP == "" {
+		return e.Detail
+	}
+	return e.CP + ": " + e.Detail
+}
+
+// ValidationErrors 校验错误列表，ValidateCodePoint 返回的 error 可以用 errors.As 取出
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	switch len(errs) {
+	case 0:
+		return "no validation errors"
+	case 1:
+		return errs[0].Error()
+	default:
+		return fmt.Sprintf("%s (and %d more errors)", errs[0].Error(), len(errs)-1)
+	}
+}
+
+// Skip 是否包含结构错误，包含时条目应被跳过
+func (errs ValidationErrors) Skip() bool {
+	for _, e := range errs {
+		if skipped[e.Kind] {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationReport ProcessUCDForMongoDB 的校验结果
+type ValidationReport struct {
+	Errors  ValidationErrors `json:"errors"`
+	Skipped int              `json:"skipped"` // 因结构错误被跳过的条目数
+}
+
+// Count 某一类错误的数量
+func (r *ValidationReport) Count(kind ValidationKind) int {
+	n := 0
+	for _, e := range r.Errors {
+		if e.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Messages 每个错误一行的描述
+func (r *ValidationReport) Messages() []string {
+	messages := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		messages[i] = string(e.Kind) + ": " + e.Error()
+	}
+	return messages
+}
+
+// codePointPattern UCD XML 中字符点的写法
+var codePointPattern = regexp.MustCompile(`^[0-9A-F]{4,6}$`)
+
+// scriptPattern ISO 15924 代码，具体取值随 Unicode 版本增加，只检查格式
+var scriptPattern = regexp.MustCompile(`^[A-Z][a-z]{3}$`)
+
+// generalCategories General_Category 的全部取值
+var generalCategories = setOf(
+	"Lu", "Ll", "Lt", "Lm", "Lo",
+	"Mn", "Mc", "Me",
+	"Nd", "Nl", "No",
+	"Pc", "Pd", "Ps", "Pe", "Pi", "Pf", "Po",
+	"Sm", "Sc", "Sk", "So",
+	"Zs", "Zl", "Zp",
+	"Cc", "Cf", "Cs", "Co", "Cn",
+)
+
+// bidiClasses Bidi_Class 的全部取值
+var bidiClasses = setOf(
+	"L", "R", "AL",
+	"EN", "ES", "ET", "AN", "CS", "NSM", "BN",
+	"B", "S", "WS", "ON",
+	"LRE", "LRO", "RLE", "RLO", "PDF", "LRI", "RLI", "FSI", "PDI",
+)
+
+func setOf(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// ValidateCodePoint 验证单个条目的字符点和属性值，错误类型为 ValidationErrors
+func ValidateCodePoint(cp *CodePoint) error {
+	errs := validateCodePoint(cp)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateCodePoint(cp *CodePoint) ValidationErrors {
+	var errs ValidationErrors
+	label := codePointLabel(cp)
+	add := func(kind ValidationKind, format string, args ...any) {
+		errs = append(errs, &ValidationError{Kind: kind, CP: label, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case cp.CP == "" && cp.FirstCP == "":
+		add(MissingCodePoint, "code point must have either cp or first-cp")
+		return errs
+	case cp.CP != "" && (cp.FirstCP != "" || cp.LastCP != ""):
+		add(ConflictingCodePoint, "code point must not have both cp and first-cp/last-cp")
+		return errs
+	case cp.FirstCP != "" && cp.LastCP == "":
+		// 如果有范围，检查last-cp
+		add(MissingLastCP, "code point with first-cp must also have last-cp")
+		return errs
+	}
+
+	values := []struct{ attr, value string }{{"cp", cp.CP}, {"first-cp", cp.FirstCP}, {"last-cp", cp.LastCP}}
+	for _, v := range values {
+		if v.value == "" {
+			continue
+		}
+		if !codePointPattern.MatchString(v.value) {
+			add(InvalidHex, "%s %q is not 4 to 6 uppercase hex digits", v.attr, v.value)
+			continue
+		}
+		if r, _ := ParseCodePoint(v.value); r > MaxCodePoint {
+			add(OutOfRange, "%s %s is above 10FFFF", v.attr, v.value)
+		}
+	}
+	if errs.Skip() {
+		return errs
+	}
+
+	if first, last, ok := codePointSpan(cp); ok && first > last {
+		add(ReversedRange, "first-cp %s is greater than last-cp %s", cp.FirstCP, cp.LastCP)
+	}
+
+	if cp.GeneralCategory != "" && !generalCategories[cp.GeneralCategory] {
+		add(UnknownGeneralCategory, "unknown gc %q", cp.GeneralCategory)
+	}
+	if cp.BidiClass != "" && !bidiClasses[cp.BidiClass] {
+		add(UnknownBidiClass, "unknown bc %q", cp.BidiClass)
+	}
+	if cp.Script != "" && !scriptPattern.MatchString(cp.Script) {
+		add(UnknownScript, "unknown sc %q", cp.Script)
+	}
+
+	return errs
+}
+
+// ValidateRepertoire 检查条目之间的重复和重叠、不在任何块中的字符，
+// 以及 checkCoverage 时 0..10FFFF 的空缺
+//
+// 存在结构错误的条目不参与检查。blocks 为空时不检查块。
+func ValidateRepertoire(r *Repertoire, blocks []Block, checkCoverage bool) ValidationErrors {
+	if r == nil {
+		return nil
+	}
+
+	type span struct {
+		first, last rune
+		label       string
+	}
+	var spans []span
+	var chars []span
+	// 保留字符和非字符可以不在任何块中，例如 2FE0..2FEF 和 1FFFE
+	kinds := []struct {
+		list    []CodePoint
+		inBlock bool
+	}{
+		{r.CodePoints, true},
+		{r.Reserved, false},
+		{r.Noncharacter, false},
+		{r.Surrogate, true},
+	}
+	for _, kind := range kinds {
+		for i := range kind.list {
+			cp := &kind.list[i]
+			first, last, ok := codePointSpan(cp)
+			if !ok || first > last || last > MaxCodePoint {
+				continue
+			}
+			s := span{first, last, codePointLabel(cp)}
+			spans = append(spans, s)
+			if kind.inBlock {
+				chars = append(chars, s)
+			}
+		}
+	}
+
+	var errs ValidationErrors
+	add := func(kind ValidationKind, label, format string, args ...any) {
+		errs = append(errs, &ValidationError{Kind: kind, CP: label, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].first < spans[j].first })
+	covered := rune(-1) // 已覆盖到的最大字符点
+	var previous span
+	for _, s := range spans {
+		switch {
+		case s.first <= covered && s.first == s.last && previous.first == previous.last && s.first == previous.first:
+			add(DuplicateCodePoint, s.label, "code point appears more than once")
+		case s.first <= covered:
+			add(OverlappingRange, s.label, "overlaps %s", previous.label)
+		case checkCoverage && s.first > covered+1:
+			add(CoverageGap, rangeLabel(covered+1, s.first-1), "no entry covers these code points")
+		}
+		if s.last > covered {
+			covered = s.last
+			previous = s
+		}
+	}
+	if checkCoverage && covered < MaxCodePoint {
+		add(CoverageGap, rangeLabel(covered+1, MaxCodePoint), "no entry covers these code points")
+	}
+
+	if len(blocks) > 0 {
+		index := newBlockIndex(blocks)
+		for _, c := range chars {
+			if !index.contains(c.first, c.last) {
+				add(OutsideBlock, c.label, "not inside any block")
+			}
+		}
+	}
+
+	return errs
+}
+
+// blockIndex 按起点排序的块范围
+type blockIndex []struct{ first, last rune }
+
+func newBlockIndex(blocks []Block) blockIndex {
+	var index blockIndex
+	for _, b := range blocks {
+		first, err1 := ParseCodePoint(b.FirstCP)
+		last, err2 := ParseCodePoint(b.LastCP)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		index = append(index, struct{ first, last rune }{first, last})
+	}
+	sort.Slice(index, func(i, j int) bool { return index[i].first < index[j].first })
+	return index
+}
+
+// contains first..last 是否完全在某一个块中
+func (index blockIndex) contains(first, last rune) bool {
+	i := sort.Search(len(index), func(i int) bool { return index[i].first > first }) - 1
+	return i >= 0 && last <= index[i].last
+}
+
+// codePointSpan 条目的范围，单个字符点时 first 等于 last
+func codePointSpan(cp *CodePoint) (rune, rune, bool) {
+	if cp.CP != "" {
+		r, err := ParseCodePoint(cp.CP)
+		return r, r, err == nil
+	}
+	first, err := ParseCodePoint(cp.FirstCP)
+	if err != nil {
+		return 0, 0, false
+	}
+	last, err := ParseCodePoint(cp.LastCP)
+	if err != nil {
+		return 0, 0, false
+	}
+	return first, last, true
+}
+
+// codePointLabel 条目的描述，例如 0041 或 4E00..9FFF
+func codePointLabel(cp *CodePoint) string {
+	if cp.CP != "" {
+		return cp.CP
+	}
+	if cp.FirstCP == "" || cp.LastCP == "" {
+		return cp.FirstCP + cp.LastCP
+	}
+	return cp.FirstCP + ".." + cp.LastCP
+}
+
+func rangeLabel(first, last rune) string {
+	if first == last {
+		return FormatCodePoint(first)
+	}
+	return FormatCodePoint(first) + ".." + FormatCodePoint(last)
+}
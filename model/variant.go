@@ -0,0 +1,135 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// See: https://www.unicode.org/reports/tr42/#d1e2673
+type Variant string
+
+const (
+	VariantAll      Variant = "all"      // ucd.all.flat.xml
+	VariantNoUnihan Variant = "nounihan" // ucd.nounihan.flat.xml
+	VariantUnihan   Variant = "unihan"   // ucd.unihan.flat.xml
+	VariantCombined Variant = "combined" // nounihan + unihan 合并
+)
+
+// ParseVariant 解析变体名称，空字符串默认为 all
+func ParseVariant(s string) (Variant, error) {
+	switch v := Variant(strings.ToLower(strings.TrimSpace(s))); v {
+	case "":
+		return VariantAll, nil
+	case VariantAll, VariantNoUnihan, VariantUnihan, VariantCombined:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown UCD variant %q", s)
+	}
+}
+
+// FileName 返回变体对应的文件名（不含扩展名），combined 没有单独的文件
+func (v Variant) FileName() string {
+	return "ucd." + string(v) + ".flat"
+}
+
+// MergeUnihan 将 unihan 变体中的 Unihan 属性合并到 nounihan 变体的字符点上
+//
+// nounihan 中以 first-cp/last-cp 表示的范围，如果包含带 Unihan 属性的字符，
+// 会被展开为单个字符点，与 all 变体保持一致。返回没有对应字符点的 Unihan 条目数。
+func MergeUnihan(base, unihan *UCD) (int, error) {
+	if base.Repertoire == nil || unihan.Repertoire == nil {
+		return 0, nil
+	}
+
+	props := make(map[rune]*CodePointProperties)
+	for i := range unihan.Repertoire.CodePoints {
+		cp := &unihan.Repertoire.CodePoints[i]
+		if cp.CP == "" {
+			continue
+		}
+		r, err := ParseCodePoint(cp.CP)
+		if err != nil {
+			return 0, err
+		}
+		props[r] = &cp.CodePointProperties
+	}
+
+	merged := make([]CodePoint, 0, len(base.Repertoire.CodePoints))
+	for _, cp := range base.Repertoire.CodePoints {
+		if cp.CP != "" {
+			r, err := ParseCodePoint(cp.CP)
+			if err != nil {
+				return 0, err
+			}
+			if p, ok := props[r]; ok {
+				mergeUnihanProperties(&cp.CodePointProperties, p)
+				delete(props, r)
+			}
+			merged = append(merged, cp)
+			continue
+		}
+
+		first, err := ParseCodePoint(cp.FirstCP)
+		if err != nil {
+			return 0, err
+		}
+		last, err := ParseCodePoint(cp.LastCP)
+		if err != nil {
+			return 0, err
+		}
+		if !rangeHasUnihan(props, first, last) {
+			merged = append(merged, cp)
+			continue
+		}
+
+		// 展开范围
+		for r := first; r <= last; r++ {
+			single := cp
+			single.CP = FormatCodePoint(r)
+			single.FirstCP = ""
+			single.LastCP = ""
+			if p, ok := props[r]; ok {
+				mergeUnihanProperties(&single.CodePointProperties, p)
+				delete(props, r)
+			}
+			merged = append(merged, single)
+		}
+	}
+
+	base.Repertoire.CodePoints = merged
+	return len(props), nil
+}
+
+// rangeHasUnihan 检查范围内是否有字符带 Unihan 属性
+func rangeHasUnihan(props map[rune]*CodePointProperties, first, last rune) bool {
+	for r := first; r <= last; r++ {
+		if _, ok := props[r]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeUnihanProperties 复制非空的 Unihan 属性
+func mergeUnihanProperties(dst, src *CodePointProperties) {
+	fields := []struct {
+		dst *string
+		src string
+	}{
+		{&dst.KDefinition, src.KDefinition},
+		{&dst.KMandarin, src.KMandarin},
+		{&dst.KCantonese, src.KCantonese},
+		{&dst.KJapaneseKun, src.KJapaneseKun},
+		{&dst.KJapaneseOn, src.KJapaneseOn},
+		{&dst.KKorean, src.KKorean},
+		{&dst.KVietnamese, src.KVietnamese},
+		{&dst.KTotalStrokes, src.KTotalStrokes},
+		{&dst.KSimplifiedVariant, src.KSimplifiedVariant},
+		{&dst.KTraditionalVariant, src.KTraditionalVariant},
+	}
+	for _, f := range fields {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+}
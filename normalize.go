@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"udc2mongo/model"
+	"udc2mongo/normalize"
+)
+
+// runNormalize 用导入版本的分解数据规范化文本，或者运行 NormalizationTest.txt
+func runNormalize(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("normalize", flag.ExitOnError)
+	source := flags.String("source", "xml", "read from the UCD XML (xml) or from MONGODB_DB (mongo)")
+	formName := flags.String("form", "NFC", "NFC, NFD, NFKC or NFKD")
+	codePointsOut := flags.Bool("codepoints", false, "print the code points of the result instead of the text")
+	test := flags.Bool("test", false, "run the official NormalizationTest.txt for the loaded version")
+	fixtures := flags.String("fixtures", "", "directory with NormalizationTest.txt, downloaded when empty")
+	flags.Parse(args)
+
+	form, err := normalize.ParseForm(*formName)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	if !*test {
+		text = strings.Join(flags.Args(), " ")
+		if text == "" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("error reading standard input: %w", err)
+			}
+			text = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		}
+		if text == "" {
+			return fmt.Errorf("usage: normalize [-form NFC|NFD|NFKC|NFKD] <text>, or normalize -test")
+		}
+	}
+
+	var version string
+	var codePoints []model.CodePoint
+	switch *source {
+	case "xml":
+		version = ucdVersion()
+		codePoints, _, err = loadFromXML(ctx, version)
+	case "mongo":
+		version, codePoints, _, err = loadFromMongo(ctx, mongoDBName())
+	default:
+		err = fmt.Errorf("unknown source %q", *source)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading data: %w", err)
+	}
+
+	tables, err := normalize.NewTables(codePoints)
+	if err != nil {
+		return fmt.Errorf("error building normalization tables: %w", err)
+	}
+
+	if *test {
+		return runNormalizationTests(ctx, tables, version, *fixtures)
+	}
+
+	result := tables.String(form, text)
+	if *codePointsOut {
+		fmt.Println(normalize.Format(result))
+		return nil
+	}
+	fmt.Println(result)
+	return nil
+}
+
+// runNormalizationTests 运行 NormalizationTest.txt，包括 Part 1 以外字符点不变的检查
+func runNormalizationTests(ctx context.Context, tables *normalize.Tables, version, dir string) error {
+	const fileName = "NormalizationTest.txt"
+
+	var content []byte
+	var err error
+	if dir != "" {
+		content, err = os.ReadFile(filepath.Join(dir, fileName))
+	} else {
+		content, _, err = fetchUcdTextWithCache(ctx, version, fileName)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading %s: %w", fileName, err)
+	}
+
+	cases, err := normalize.ParseTests(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", fileName, err)
+	}
+
+	failures := tables.RunTests(cases)
+	if len(failures) == 0 {
+		fmt.Printf("✓ %s passes (%d cases)\n", fileName, len(cases))
+		return nil
+	}
+
+	fmt.Printf("✗ %s: %d failures in %d cases\n", fileName, len(failures), len(cases))
+	for i, failure := range failures {
+		if i >= 5 { // 只显示前5个
+			break
+		}
+		if failure.Line == 0 {
+			fmt.Printf("  %s(%s) should be unchanged, got %s\n", failure.Form, normalize.Format(failure.Input), normalize.Format(failure.Got))
+			continue
+		}
+		fmt.Printf("  line %d: %s(c%d) = %s, want %s\n", failure.Line, failure.Form, failure.Column,
+			normalize.Format(failure.Got), normalize.Format(failure.Want))
+	}
+	return fmt.Errorf("normalization does not match the Unicode %s test file", version)
+}
@@ -0,0 +1,148 @@
+package normalize
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"udc2mongo/model"
+)
+
+// TestCase NormalizationTest.txt 中的一行，c1 到 c5 五列
+//
+// See: https://www.unicode.org/Public/UCD/latest/ucd/NormalizationTest.txt
+type TestCase struct {
+	Line    int
+	Part    string // 所在的 @Part，例如 @Part1
+	Columns [5]string
+}
+
+// TestFailure 不满足的一项要求，例如 c2 == NFC(c1)
+type TestFailure struct {
+	Line   int // Part 1 以外字符的检查为 0
+	Form   Form
+	Column int // 输入所在的列，从 1 开始；Part 1 以外字符的检查为 0
+	Input  string
+	Want   string
+	Got    string
+}
+
+// ParseTests 解析 NormalizationTest.txt
+func ParseTests(r io.Reader) ([]TestCase, error) {
+	var cases []TestCase
+	part := ""
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			part = line
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("line %d: expected 5 columns, got %d", lineNo, len(fields))
+		}
+		tc := TestCase{Line: lineNo, Part: part}
+		for i := range tc.Columns {
+			s, err := parseColumn(fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			tc.Columns[i] = s
+		}
+		cases = append(cases, tc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// parseColumn 解析以空格分隔的十六进制字符点
+func parseColumn(s string) (string, error) {
+	var sb strings.Builder
+	for _, field := range strings.Fields(s) {
+		r, err := model.ParseCodePoint(field)
+		if err != nil {
+			return "", err
+		}
+		if !utf8.ValidRune(r) {
+			return "", fmt.Errorf("invalid code point %q", field)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String(), nil
+}
+
+// conformance 每种形式的要求：want 是期望结果所在的列，inputs 是要检查的列
+//
+// See: NormalizationTest.txt 文件头的 CONFORMANCE 一节
+var conformance = []struct {
+	form   Form
+	want   int
+	inputs []int
+}{
+	{NFC, 2, []int{1, 2, 3}},
+	{NFC, 4, []int{4, 5}},
+	{NFD, 3, []int{1, 2, 3}},
+	{NFD, 5, []int{4, 5}},
+	{NFKC, 4, []int{1, 2, 3, 4, 5}},
+	{NFKD, 5, []int{1, 2, 3, 4, 5}},
+}
+
+// RunTests 检查每一行的全部要求，以及 Part 1 没有列出的字符点在四种形式下都不变
+func (t *Tables) RunTests(cases []TestCase) []TestFailure {
+	var failures []TestFailure
+	listed := make(map[rune]bool)
+	for _, tc := range cases {
+		if tc.Part == "@Part1" {
+			if r, size := utf8.DecodeRuneInString(tc.Columns[0]); size == len(tc.Columns[0]) {
+				listed[r] = true
+			}
+		}
+
+		for _, c := range conformance {
+			want := tc.Columns[c.want-1]
+			for _, column := range c.inputs {
+				input := tc.Columns[column-1]
+				if got := t.String(c.form, input); got != want {
+					failures = append(failures, TestFailure{
+						Line: tc.Line, Form: c.form, Column: column, Input: input, Want: want, Got: got,
+					})
+				}
+			}
+		}
+	}
+
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if listed[r] || !utf8.ValidRune(r) {
+			continue
+		}
+		s := string(r)
+		for _, f := range Forms {
+			if got := t.String(f, s); got != s {
+				failures = append(failures, TestFailure{Form: f, Input: s, Want: s, Got: got})
+			}
+		}
+	}
+	return failures
+}
+
+// Format 以测试文件的格式显示字符点，例如 "0041 0301"
+func Format(s string) string {
+	fields := make([]string, 0, len(s))
+	for _, r := range s {
+		fields = append(fields, model.FormatCodePoint(r))
+	}
+	return strings.Join(fields, " ")
+}
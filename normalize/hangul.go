@@ -0,0 +1,43 @@
+package normalize
+
+// Hangul 音节的算法分解和组合
+//
+// See: https://www.unicode.org/versions/latest/core-spec/chapter-3/#G56669
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+func isHangulSyllable(r rune) bool {
+	return hangulSBase <= r && r < hangulSBase+hangulSCount
+}
+
+// decomposeHangul 把音节分解为 L V 或 L V T 追加到 buf
+func decomposeHangul(buf []rune, r rune) []rune {
+	s := r - hangulSBase
+	l := hangulLBase + s/hangulNCount
+	v := hangulVBase + (s%hangulNCount)/hangulTCount
+	buf = append(buf, l, v)
+	if t := s % hangulTCount; t != 0 {
+		buf = append(buf, hangulTBase+t)
+	}
+	return buf
+}
+
+// composeHangul 组合 L+V 或 LV+T
+func composeHangul(a, b rune) (rune, bool) {
+	if hangulLBase <= a && a < hangulLBase+hangulLCount && hangulVBase <= b && b < hangulVBase+hangulVCount {
+		return hangulSBase + ((a-hangulLBase)*hangulVCount+(b-hangulVBase))*hangulTCount, true
+	}
+	if isHangulSyllable(a) && (a-hangulSBase)%hangulTCount == 0 && hangulTBase < b && b < hangulTBase+hangulTCount {
+		return a + (b - hangulTBase), true
+	}
+	return 0, false
+}
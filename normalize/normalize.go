@@ -0,0 +1,196 @@
+// Package normalize 按 UAX #15 把文本规范化为 NFC、NFD、NFKC 或 NFKD
+//
+// 数据来自导入的 UCD，结果与导入的 Unicode 版本一致，不依赖 golang.org/x/text 编译进来的版本。
+//
+// See: https://www.unicode.org/reports/tr15/
+package normalize
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Form 规范化形式
+type Form string
+
+const (
+	NFC  Form = "NFC"
+	NFD  Form = "NFD"
+	NFKC Form = "NFKC"
+	NFKD Form = "NFKD"
+)
+
+// Forms 全部规范化形式，顺序与 quick check 的下标一致
+var Forms = []Form{NFC, NFD, NFKC, NFKD}
+
+// ParseForm 解析规范化形式，不区分大小写
+func ParseForm(s string) (Form, error) {
+	switch f := Form(strings.ToUpper(s)); f {
+	case NFC, NFD, NFKC, NFKD:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown normalization form %q, expected NFC, NFD, NFKC or NFKD", s)
+}
+
+func (f Form) index() int {
+	switch f {
+	case NFC:
+		return 0
+	case NFD:
+		return 1
+	case NFKC:
+		return 2
+	case NFKD:
+		return 3
+	}
+	panic("normalize: unknown form " + string(f))
+}
+
+// compatible 是否使用兼容分解
+func (f Form) compatible() bool {
+	return f == NFKC || f == NFKD
+}
+
+// composed 分解后是否再组合
+func (f Form) composed() bool {
+	return f == NFC || f == NFKC
+}
+
+// String 把 s 规范化为 f，不合法的 UTF-8 字节替换为 U+FFFD
+//
+// quick check 为 Yes 时直接返回 s。
+func (t *Tables) String(f Form, s string) string {
+	if t.quickCheck(f, s) == qcYes {
+		return s
+	}
+	return string(t.normalize(f, []rune(s)))
+}
+
+// IsNormalized 检查 s 是否已经是 f 形式
+func (t *Tables) IsNormalized(f Form, s string) bool {
+	switch t.quickCheck(f, s) {
+	case qcYes:
+		return true
+	case qcNo:
+		return false
+	}
+	return string(t.normalize(f, []rune(s))) == s
+}
+
+// quickCheck UAX #15 的 quick check，不合法的 UTF-8 返回 No
+//
+// See: https://www.unicode.org/reports/tr15/#Detecting_Normalization_Forms
+func (t *Tables) quickCheck(f Form, s string) quickCheck {
+	result := qcYes
+	var last uint8
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return qcNo
+			}
+		}
+
+		ccc := t.ccc(r)
+		if ccc != 0 && last > ccc {
+			return qcNo
+		}
+		switch t.runeQuickCheck(f, r) {
+		case qcNo:
+			return qcNo
+		case qcMaybe:
+			result = qcMaybe
+		}
+		last = ccc
+	}
+	return result
+}
+
+// runeQuickCheck 字符的 NFx_QC
+func (t *Tables) runeQuickCheck(f Form, r rune) quickCheck {
+	if isHangulSyllable(r) && !f.composed() {
+		return qcNo
+	}
+	if e := t.entries[r]; e != nil {
+		return e.qc[f.index()]
+	}
+	return qcYes
+}
+
+// normalize 分解、规范排序，需要时再组合
+func (t *Tables) normalize(f Form, runes []rune) []rune {
+	buf := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		buf = t.decompose(buf, r, f.compatible())
+	}
+	t.reorder(buf)
+	if f.composed() {
+		buf = t.composeAll(buf)
+	}
+	return buf
+}
+
+// decompose 把 r 的完全分解追加到 buf
+func (t *Tables) decompose(buf []rune, r rune, compatible bool) []rune {
+	if isHangulSyllable(r) {
+		return decomposeHangul(buf, r)
+	}
+	if e := t.entries[r]; e != nil {
+		if compatible && e.compatibility != nil {
+			return append(buf, e.compatibility...)
+		}
+		if e.canonical != nil {
+			return append(buf, e.canonical...)
+		}
+	}
+	return append(buf, r)
+}
+
+// reorder 规范排序：连续的非起始字符按 ccc 稳定排序
+//
+// See: https://www.unicode.org/versions/latest/core-spec/chapter-3/#G49591
+func (t *Tables) reorder(buf []rune) {
+	for i := 1; i < len(buf); i++ {
+		ccc := t.ccc(buf[i])
+		if ccc == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			prev := t.ccc(buf[j-1])
+			if prev == 0 || prev <= ccc {
+				break
+			}
+			buf[j-1], buf[j] = buf[j], buf[j-1]
+		}
+	}
+}
+
+// composeAll 规范组合，字符与最近的起始字符之间没有阻断时组合
+//
+// See: https://www.unicode.org/versions/latest/core-spec/chapter-3/#G50628
+func (t *Tables) composeAll(buf []rune) []rune {
+	if len(buf) == 0 {
+		return buf
+	}
+
+	starter := 0
+	last := t.ccc(buf[0])
+	if last != 0 {
+		last = 255 // 以非起始字符开头时，后面的字符不能与它组合
+	}
+	out := 1
+	for _, r := range buf[1:] {
+		ccc := t.ccc(r)
+		if composite, ok := t.compose(buf[starter], r); ok && (last < ccc || last == 0) {
+			buf[starter] = composite
+			continue
+		}
+		if ccc == 0 {
+			starter = out
+		}
+		last = ccc
+		buf[out] = r
+		out++
+	}
+	return buf[:out]
+}
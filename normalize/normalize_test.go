@@ -0,0 +1,131 @@
+package normalize
+
+import (
+	"os"
+	"testing"
+	"unicode/utf8"
+
+	"udc2mongo/model"
+)
+
+// testdata 是 Unicode 14.0.0 的数据，但不是官方文件：NormalizationTest.txt 按官方格式由
+// Python 3.11 的 unicodedata（14.0.0）生成，Part 0 是手选的和随机组合的序列，
+// Part 1 列出 ucd.xml 中在某种形式下会变化的全部字符；ucd.xml 只有这些字符的规范化属性。
+//
+// Hangul 音节按算法处理，不在 ucd.xml 中，Part 1 只列出了用到的几个。
+
+func loadTables(t *testing.T) *Tables {
+	t.Helper()
+	data, err := os.ReadFile("testdata/ucd.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ucd, err := model.ParseUCDXML(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codePoints := model.ExtractAllCodePoints(ucd)
+	for i := range codePoints {
+		model.NormalizeCodePoint(&codePoints[i])
+	}
+	tables, err := NewTables(codePoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tables
+}
+
+func TestConformance(t *testing.T) {
+	tables := loadTables(t)
+	f, err := os.Open("testdata/NormalizationTest.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cases, err := ParseTests(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no test cases")
+	}
+
+	for _, failure := range tables.RunTests(cases) {
+		if failure.Line == 0 {
+			// 没有列出的 Hangul 音节会被分解，只在完整的测试文件中才有对应的行
+			if r, _ := utf8.DecodeRuneInString(failure.Input); isHangulSyllable(r) {
+				continue
+			}
+		}
+		t.Errorf("line %d: %s(c%d) of %s = %s, want %s", failure.Line, failure.Form, failure.Column,
+			Format(failure.Input), Format(failure.Got), Format(failure.Want))
+	}
+}
+
+func TestString(t *testing.T) {
+	tables := loadTables(t)
+	tests := []struct {
+		form Form
+		in   string
+		want string
+	}{
+		{NFC, "", ""},
+		{NFC, "A\u030A", "\u00C5"},
+		{NFC, "\u212B", "\u00C5"},
+		{NFD, "\u1E0A\u0323", "D\u0323\u0307"},
+		{NFC, "D\u0307\u0323", "\u1E0C\u0307"},
+		{NFC, "\u0958", "\u0915\u093C"},
+		{NFKC, "\uFB01", "fi"},
+		{NFKD, "\u00BD", "1\u20442"},
+		{NFC, "\u1100\u1161\u11A8", "\uAC01"},
+		{NFD, "\uAC01", "\u1100\u1161\u11A8"},
+		{NFC, "a\xffb", "a\uFFFDb"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.form)+"/"+Format(tt.in), func(t *testing.T) {
+			if got := tables.String(tt.form, tt.in); got != tt.want {
+				t.Errorf("String(%s, %s) = %s, want %s", tt.form, Format(tt.in), Format(got), Format(tt.want))
+			}
+		})
+	}
+}
+
+func TestIsNormalized(t *testing.T) {
+	tables := loadTables(t)
+	tests := []struct {
+		form Form
+		in   string
+		want bool
+	}{
+		{NFC, "\u00C5", true},
+		{NFD, "\u00C5", false},
+		{NFC, "A\u030A", false},
+		{NFKC, "\uFB01", false},
+		{NFC, "\uFB01", true},
+		{NFD, "\uAC00", false},
+	}
+	for _, tt := range tests {
+		if got := tables.IsNormalized(tt.form, tt.in); got != tt.want {
+			t.Errorf("IsNormalized(%s, %s) = %v, want %v", tt.form, Format(tt.in), got, tt.want)
+		}
+	}
+}
+
+func TestParseForm(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Form
+		wantErr bool
+	}{
+		{"NFC", NFC, false},
+		{"nfkd", NFKD, false},
+		{"NFX", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseForm(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseForm(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
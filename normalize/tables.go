@@ -0,0 +1,173 @@
+package normalize
+
+import (
+	"fmt"
+	"strings"
+
+	"udc2mongo/model"
+)
+
+// quickCheck NFC_QC、NFKC_QC 等的取值
+type quickCheck uint8
+
+const (
+	qcYes quickCheck = iota
+	qcNo
+	qcMaybe
+)
+
+// entry 单个字符点的规范化数据
+type entry struct {
+	ccc uint8
+
+	canonical     []rune // 完全展开的规范分解，没有时为 nil
+	compatibility []rune // 完全展开的兼容分解，包括规范分解
+
+	qc [4]quickCheck // 按 Form 的顺序
+}
+
+// Tables 从导入的 UCD 建立的规范化数据
+type Tables struct {
+	entries  map[rune]*entry
+	composes map[[2]rune]rune // 规范组合，不包括 Full_Composition_Exclusion 的字符
+}
+
+// NewTables 从解析后的字符点建立规范化数据
+//
+// 分解在建立时递归展开；组合表从 dt=can 的双字符映射生成，排除 CE、单字符映射和以非起始字符开头的映射。
+// Hangul 音节不使用 dm，按算法分解和组合。
+func NewTables(codePoints []model.CodePoint) (*Tables, error) {
+	t := &Tables{
+		entries:  make(map[rune]*entry),
+		composes: make(map[[2]rune]rune),
+	}
+
+	canonical := make(map[rune][]rune)
+	compatibility := make(map[rune][]rune)
+	excluded := make(map[rune]bool)
+
+	for i := range codePoints {
+		cp := &codePoints[i]
+		if cp.CP == "" {
+			// 范围条目没有分解，ccc 为 0；Hangul 音节的 NFD_QC、NFKD_QC 在 quickCheck 中按算法处理
+			continue
+		}
+		r, err := model.ParseCodePoint(cp.CP)
+		if err != nil {
+			return nil, err
+		}
+
+		var e entry
+		if cp.CombiningClass < 0 || cp.CombiningClass > 254 {
+			return nil, fmt.Errorf("%s: invalid ccc %d", cp.CP, cp.CombiningClass)
+		}
+		e.ccc = uint8(cp.CombiningClass)
+
+		for i, value := range []string{cp.NFC_QC, cp.NFD_QC, cp.NFKC_QC, cp.NFKD_QC} {
+			qc, err := parseQuickCheck(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", cp.CP, err)
+			}
+			e.qc[i] = qc
+		}
+		if e.ccc != 0 || e.qc != [4]quickCheck{} {
+			t.entries[r] = &e
+		}
+
+		if cp.DecompositionType == "" || cp.DecompositionType == "none" || cp.DecompositionMapping == "" || isHangulSyllable(r) {
+			continue
+		}
+		mapping, err := parseMapping(cp.DecompositionMapping)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cp.CP, err)
+		}
+		compatibility[r] = mapping
+		if cp.DecompositionType == "can" {
+			canonical[r] = mapping
+		}
+		if cp.CompositionExclusion || cp.FullCompositionExclusion {
+			excluded[r] = true
+		}
+	}
+
+	for r := range compatibility {
+		e := t.entries[r]
+		if e == nil {
+			e = &entry{}
+			t.entries[r] = e
+		}
+		if _, ok := canonical[r]; ok {
+			e.canonical = expand(r, canonical)
+		}
+		e.compatibility = expand(r, compatibility)
+	}
+
+	// 组合表在分解之后生成，需要用到 ccc
+	for r, mapping := range canonical {
+		if excluded[r] || len(mapping) != 2 || t.ccc(r) != 0 || t.ccc(mapping[0]) != 0 {
+			continue
+		}
+		t.composes[[2]rune{mapping[0], mapping[1]}] = r
+	}
+	return t, nil
+}
+
+// expand 递归展开分解映射
+func expand(r rune, mappings map[rune][]rune) []rune {
+	mapping, ok := mappings[r]
+	if !ok {
+		if isHangulSyllable(r) {
+			return decomposeHangul(nil, r)
+		}
+		return []rune{r}
+	}
+	var result []rune
+	for _, m := range mapping {
+		result = append(result, expand(m, mappings)...)
+	}
+	return result
+}
+
+// parseMapping 解析以空格分隔的十六进制字符点
+func parseMapping(s string) ([]rune, error) {
+	fields := strings.Fields(s)
+	mapping := make([]rune, 0, len(fields))
+	for _, field := range fields {
+		r, err := model.ParseCodePoint(field)
+		if err != nil {
+			return nil, err
+		}
+		mapping = append(mapping, r)
+	}
+	return mapping, nil
+}
+
+// parseQuickCheck 解析 Y、N、M，空值视为 Y
+func parseQuickCheck(s string) (quickCheck, error) {
+	switch s {
+	case "", "Y":
+		return qcYes, nil
+	case "N":
+		return qcNo, nil
+	case "M":
+		return qcMaybe, nil
+	}
+	return qcYes, fmt.Errorf("invalid quick check value %q", s)
+}
+
+// ccc 字符的 Canonical_Combining_Class
+func (t *Tables) ccc(r rune) uint8 {
+	if e := t.entries[r]; e != nil {
+		return e.ccc
+	}
+	return 0
+}
+
+// compose 两个字符的规范组合，没有时返回 false
+func (t *Tables) compose(a, b rune) (rune, bool) {
+	if r, ok := composeHangul(a, b); ok {
+		return r, true
+	}
+	r, ok := t.composes[[2]rune{a, b}]
+	return r, ok
+}
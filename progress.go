@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"udc2mongo/progress"
+)
+
+// reporter 命令行的进度报告，由全局参数 -progress 决定
+var reporter = progress.Discard
+
+// newReporter 创建进度报告：bar 为终端进度条，lines 每 5 秒输出一行，auto 按 stderr 是否为终端选择
+func newReporter(mode string) (progress.Reporter, error) {
+	switch mode {
+	case "", "auto":
+		if isTerminal(os.Stderr) {
+			return progress.NewBar(os.Stderr), nil
+		}
+		return progress.NewLines(os.Stderr, 5*time.Second), nil
+	case "bar":
+		return progress.NewBar(os.Stderr), nil
+	case "lines":
+		return progress.NewLines(os.Stderr, 5*time.Second), nil
+	case "none":
+		return progress.Discard, nil
+	default:
+		return nil, fmt.Errorf("unknown progress mode %q", mode)
+	}
+}
+
+// isTerminal 检查文件是否为字符设备
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,148 @@
+// Package progress 报告下载、解析和写入等阶段的进度
+//
+// 库代码通过 Reporter.Start 开始一个阶段，得到的 Tracker 可以并发调用 Add。
+// 命令行在终端中使用 NewBar，在 CI 日志中使用 NewLines。
+package progress
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Unit 进度的计数单位
+type Unit string
+
+const (
+	Bytes     Unit = "bytes"
+	Elements  Unit = "elements"
+	Documents Unit = "docs"
+)
+
+// Stage 一个阶段，Total 未知时为 -1
+//
+// 下载阶段命名为 "download <文件>"，写入阶段命名为 "write <集合>"。
+type Stage struct {
+	Name  string
+	Unit  Unit
+	Total int64
+}
+
+// Reporter 接收各阶段的进度
+type Reporter interface {
+	Start(stage Stage) Tracker
+}
+
+// Tracker 单个阶段的进度，Add 可以并发调用，Done 只调用一次
+type Tracker interface {
+	Add(n int64)
+	Done()
+}
+
+// Discard 不报告任何进度
+var Discard Reporter = discard{}
+
+type discard struct{}
+
+func (discard) Start(Stage) Tracker { return discard{} }
+func (discard) Add(int64)           {}
+func (discard) Done()               {}
+
+// Reader 包装 r，读取的字节数计入 t
+func Reader(r io.Reader, t Tracker) io.Reader {
+	return &reader{r: r, t: t}
+}
+
+type reader struct {
+	r io.Reader
+	t Tracker
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.t.Add(int64(n))
+	}
+	return n, err
+}
+
+// Snapshot 某一时刻的进度
+type Snapshot struct {
+	Stage   Stage
+	Current int64
+	Elapsed time.Duration
+}
+
+// Fraction 完成比例，Total 未知时返回 -1
+func (s Snapshot) Fraction() float64 {
+	if s.Stage.Total <= 0 {
+		return -1
+	}
+	return min(float64(s.Current)/float64(s.Stage.Total), 1)
+}
+
+// Rate 每秒处理的数量
+func (s Snapshot) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Current) / s.Elapsed.Seconds()
+}
+
+// ETA 按当前速度估计的剩余时间，无法估计时返回 -1
+func (s Snapshot) ETA() time.Duration {
+	rate := s.Rate()
+	if s.Stage.Total <= 0 || rate <= 0 {
+		return -1
+	}
+	remaining := float64(max(s.Stage.Total-s.Current, 0))
+	return time.Duration(remaining / rate * float64(time.Second))
+}
+
+// counter 记录阶段开始时间和已处理数量，供各渲染器共用
+type counter struct {
+	stage   Stage
+	start   time.Time
+	current atomic.Int64
+}
+
+func newCounter(stage Stage) *counter {
+	return &counter{stage: stage, start: time.Now()}
+}
+
+func (c *counter) snapshot() Snapshot {
+	return Snapshot{Stage: c.stage, Current: c.current.Load(), Elapsed: time.Since(c.start)}
+}
+
+// formatAmount 按单位格式化数量，字节使用 KB、MB
+func formatAmount(n float64, unit Unit) string {
+	if unit != Bytes {
+		return fmt.Sprintf("%.0f", n)
+	}
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", n/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", n/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", n/(1<<10))
+	default:
+		return fmt.Sprintf("%.0f B", n)
+	}
+}
+
+// describe 数量、比例、速度和剩余时间，例如 "12.0 MB / 24.0 MB (50%), 3.0 MB/s, ETA 4s"
+func describe(s Snapshot) string {
+	unit := s.Stage.Unit
+	text := formatAmount(float64(s.Current), unit)
+	if f := s.Fraction(); f >= 0 {
+		text += fmt.Sprintf(" / %s (%.0f%%)", formatAmount(float64(s.Stage.Total), unit), f*100)
+	}
+	text += unitSuffix(unit)
+	text += ", " + formatAmount(s.Rate(), unit) + unitSuffix(unit) + "/s"
+	if eta := s.ETA(); eta >= 0 && s.Current < s.Stage.Total {
+		text += ", ETA " + eta.Round(time.Second).String()
+	}
+	return text
+}
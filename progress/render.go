@@ -0,0 +1,106 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const barWidth = 30
+
+// NewBar 在终端中用一行进度条显示当前阶段，每秒最多刷新 10 次
+func NewBar(w io.Writer) Reporter {
+	return &renderer{w: w, interval: 100 * time.Millisecond, bar: true}
+}
+
+// NewLines 每隔 interval 输出一行进度，适合不支持回车覆盖的 CI 日志
+func NewLines(w io.Writer, interval time.Duration) Reporter {
+	return &renderer{w: w, interval: interval}
+}
+
+// renderer 串行输出所有阶段的进度
+type renderer struct {
+	mu       sync.Mutex
+	w        io.Writer
+	interval time.Duration
+	bar      bool
+}
+
+func (r *renderer) Start(stage Stage) Tracker {
+	t := &tracker{r: r, c: newCounter(stage)}
+	if !r.bar {
+		// 逐行输出时第一行也等待 interval，短阶段只输出完成行
+		t.last = t.c.start
+	}
+	return t
+}
+
+type tracker struct {
+	r    *renderer
+	c    *counter
+	last time.Time
+	done bool
+}
+
+func (t *tracker) Add(n int64) {
+	t.c.current.Add(n)
+
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	if t.done || time.Since(t.last) < t.r.interval {
+		return
+	}
+	t.last = time.Now()
+	t.r.draw(t, false)
+}
+
+func (t *tracker) Done() {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	if t.done {
+		return
+	}
+	t.done = true
+	t.r.draw(t, true)
+}
+
+// draw 输出一行进度，调用方持有锁
+func (r *renderer) draw(t *tracker, final bool) {
+	s := t.c.snapshot()
+
+	if !r.bar {
+		if final {
+			fmt.Fprintf(r.w, "%s: done, %s in %s\n", s.Stage.Name,
+				formatAmount(float64(s.Current), s.Stage.Unit)+unitSuffix(s.Stage.Unit),
+				s.Elapsed.Round(time.Millisecond))
+			return
+		}
+		fmt.Fprintf(r.w, "%s: %s\n", s.Stage.Name, describe(s))
+		return
+	}
+
+	line := s.Stage.Name + " " + drawBar(s.Fraction()) + " " + describe(s)
+	if final {
+		line += ", done in " + s.Elapsed.Round(time.Millisecond).String() + "\n"
+	}
+	// \r 回到行首，\033[K 清除上一次更长的内容
+	fmt.Fprint(r.w, "\r\033[K"+line)
+}
+
+// drawBar 画出进度条，比例未知时显示空条
+func drawBar(fraction float64) string {
+	if fraction < 0 {
+		return "[" + strings.Repeat(" ", barWidth) + "]"
+	}
+	filled := int(fraction * barWidth)
+	return "[" + strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled) + "]"
+}
+
+func unitSuffix(unit Unit) string {
+	if unit == Bytes {
+		return ""
+	}
+	return " " + string(unit)
+}
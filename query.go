@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"udc2mongo/database"
+	"udc2mongo/model"
+)
+
+// runQuery 按属性组合查询字符点，支持分页
+func runQuery(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	scripts := flags.String("script", "", "comma-separated sc values, short or long names")
+	scx := flags.String("scx", "", "comma-separated scx values, short or long names")
+	gc := flags.String("gc", "", "comma-separated gc values, short or long names, major classes such as L* allowed")
+	bc := flags.String("bc", "", "comma-separated bc values, short or long names")
+	ea := flags.String("ea", "", "comma-separated ea values, short or long names")
+	lb := flags.String("lb", "", "comma-separated lb values, short or long names")
+	age := flags.String("age", "", "age range such as 1.1..3.0, 6.0.. or ..4.1")
+	props := flags.String("prop", "", "comma-separated boolean properties, name or name=false")
+	blocks := flags.String("block", "", "comma-separated blk aliases or block names")
+	cpRange := flags.String("range", "", "code point range such as 0041..007A")
+	sortField := flags.String("sort", "", "field to sort by (default import order)")
+	desc := flags.Bool("desc", false, "sort descending")
+	fields := flags.String("fields", "", "comma-separated fields to return")
+	limit := flags.Int64("limit", 50, "page size, 0 for all results")
+	after := flags.String("after", "", "cursor returned by the previous page")
+	count := flags.Bool("count", false, "only print the number of matches")
+	asJSON := flags.Bool("json", false, "print the page as JSON")
+	listProps := flags.Bool("list-props", false, "list boolean properties and exit")
+	flags.Parse(args)
+
+	if *listProps {
+		for _, name := range database.BoolProperties() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	mongoClient, err := connectMongo(ctx, mongoDBName())
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Close()
+
+	q := mongoClient.Query().Limit(*limit)
+	if list := splitList(*scripts); len(list) > 0 {
+		q.Script(list...)
+	}
+	if list := splitList(*scx); len(list) > 0 {
+		q.ScriptExtensions(list...)
+	}
+	if list := splitList(*gc); len(list) > 0 {
+		q.GeneralCategory(list...)
+	}
+	if list := splitList(*bc); len(list) > 0 {
+		q.BidiClass(list...)
+	}
+	if list := splitList(*ea); len(list) > 0 {
+		q.EastAsianWidth(list...)
+	}
+	if list := splitList(*lb); len(list) > 0 {
+		q.LineBreak(list...)
+	}
+	if list := splitList(*blocks); len(list) > 0 {
+		q.Block(list...)
+	}
+	if *age != "" {
+		min, max, ok := strings.Cut(*age, "..")
+		if !ok {
+			max = min
+		}
+		q.Age(min, max)
+	}
+	for _, p := range splitList(*props) {
+		name, value, _ := strings.Cut(p, "=")
+		q.Property(name, value != "false" && value != "N")
+	}
+	if *cpRange != "" {
+		first, last, err := parseRange(*cpRange)
+		if err != nil {
+			return err
+		}
+		q.CodePointRange(first, last)
+	}
+	if *sortField != "" {
+		q.SortBy(*sortField, !*desc)
+	}
+	if list := splitList(*fields); len(list) > 0 {
+		q.Select(list...)
+	}
+
+	if *count {
+		n, err := q.Count(ctx)
+		if err != nil {
+			return fmt.Errorf("error querying: %w", err)
+		}
+		fmt.Println(n)
+		return nil
+	}
+
+	page, err := q.Page(ctx, *after)
+	if err != nil {
+		return fmt.Errorf("error querying: %w", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(page)
+	}
+
+	for _, cp := range page.CodePoints {
+		label := "U+" + cp.CP
+		if cp.CP == "" {
+			label = "U+" + cp.FirstCP + "..U+" + cp.LastCP
+		}
+		fmt.Printf("  %-8s %s %s\n", label, printableRune(cp.CP), cp.Name)
+	}
+	if page.Next != "" {
+		fmt.Printf("\nNext page: -after %s\n", page.Next)
+	}
+
+	return nil
+}
+
+// parseRange 解析 XXXX..YYYY 或单个字符点
+func parseRange(s string) (rune, rune, error) {
+	first, last, ok := strings.Cut(s, "..")
+	if !ok {
+		last = first
+	}
+	lo, err := model.ParseCodePoint(first)
+	if err != nil {
+		return 0, 0, err
+	}
+	hi, err := model.ParseCodePoint(last)
+	if err != nil {
+		return 0, 0, err
+	}
+	if lo > hi {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	return lo, hi, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// runRuns 列出或显示 import_runs 中的导入记录
+func runRuns(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("runs", flag.ExitOnError)
+	limit := flags.Int64("limit", 20, "number of runs to list")
+	show := flags.String("show", "", "print the full record of a run ID, or latest")
+	flags.Parse(args)
+
+	mongoClient, err := connectMongo(ctx, mongoDBName())
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Close()
+
+	if *show != "" {
+		id := *show
+		if id == "latest" {
+			runs, err := mongoClient.ListImportRuns(ctx, 1)
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				return fmt.Errorf("no import runs recorded")
+			}
+			id = runs[0].ID.Hex()
+		}
+
+		run, err := mongoClient.GetImportRun(ctx, id)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return fmt.Errorf("import run %s not found", id)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(run)
+	}
+
+	runs, err := mongoClient.ListImportRuns(ctx, *limit)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-24s  %-19s  %-8s  %-8s  %-11s  %-9s  %8s\n",
+		"ID", "STARTED", "VERSION", "VARIANT", "MODE", "STATUS", "DURATION")
+	for _, run := range runs {
+		duration := "-"
+		if d := run.Duration(); d > 0 {
+			duration = d.Round(time.Second).String()
+		}
+		fmt.Printf("%-24s  %-19s  %-8s  %-8s  %-11s  %-9s  %8s\n",
+			run.ID.Hex(), run.StartedAt.Local().Format(time.DateTime), run.Version,
+			run.Variant, run.Mode, run.Status, duration)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"udc2mongo/model"
+)
+
+// runSearch 按名称、别名和 Unihan 释义搜索字符
+func runSearch(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	limit := flags.Int("limit", 20, "maximum number of results")
+	flags.Parse(args)
+
+	query := strings.Join(flags.Args(), " ")
+	if query == "" {
+		return fmt.Errorf("usage: search [-limit n] <query>")
+	}
+
+	mongoClient, err := connectMongo(ctx, mongoDBName())
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Close()
+
+	results, err := mongoClient.SearchCodePoints(ctx, query, *limit)
+	if err != nil {
+		return fmt.Errorf("error searching: %w", err)
+	}
+
+	fmt.Printf("Found %d results for %q:\n", len(results), query)
+	for _, r := range results {
+		label := "U+" + r.CP
+		if r.CP == "" {
+			label = "U+" + r.FirstCP + "..U+" + r.LastCP
+		}
+		fmt.Printf("  %-8s %s %s (score %.2f)\n", label, printableRune(r.CP), r.Name, r.Score)
+	}
+
+	return nil
+}
+
+// printableRune 返回可打印的字符，否则返回空格
+func printableRune(cp string) string {
+	r, err := model.ParseCodePoint(cp)
+	if err != nil || !unicode.IsPrint(r) {
+		return " "
+	}
+	return string(r)
+}
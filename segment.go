@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"udc2mongo/model"
+	"udc2mongo/segment"
+)
+
+// segmentTestFiles UAX #29 的官方测试文件，位于 ucd/auxiliary 目录
+var segmentTestFiles = []struct {
+	name string
+	unit segment.Unit
+}{
+	{"GraphemeBreakTest.txt", segment.Grapheme},
+	{"WordBreakTest.txt", segment.Word},
+	{"SentenceBreakTest.txt", segment.Sentence},
+}
+
+// runSegment 用导入版本的断行属性切分文本，或者运行官方测试文件
+func runSegment(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("segment", flag.ExitOnError)
+	source := flags.String("source", "xml", "read from the UCD XML (xml) or from MONGODB_DB (mongo)")
+	unitName := flags.String("unit", "grapheme", "grapheme, word or sentence")
+	test := flags.Bool("test", false, "run the official break test files for the loaded version")
+	fixtures := flags.String("fixtures", "", "directory with the break test files, downloaded when empty")
+	flags.Parse(args)
+
+	unit, err := segment.ParseUnit(*unitName)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	if !*test {
+		text = strings.Join(flags.Args(), " ")
+		if text == "" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("error reading standard input: %w", err)
+			}
+			text = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		}
+		if text == "" {
+			return fmt.Errorf("usage: segment [-unit grapheme|word|sentence] <text>, or segment -test")
+		}
+	}
+
+	var version string
+	var codePoints []model.CodePoint
+	switch *source {
+	case "xml":
+		version = ucdVersion()
+		codePoints, _, err = loadFromXML(ctx, version)
+	case "mongo":
+		version, codePoints, _, err = loadFromMongo(ctx, mongoDBName())
+	default:
+		err = fmt.Errorf("unknown source %q", *source)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading data: %w", err)
+	}
+
+	props, err := segment.NewProperties(codePoints)
+	if err != nil {
+		return fmt.Errorf("error building break properties: %w", err)
+	}
+
+	if *test {
+		return runSegmentTests(ctx, props, version, *fixtures)
+	}
+
+	breaks := props.Breaks(unit, text)
+	for i := 1; i < len(breaks); i++ {
+		fmt.Printf("%6d  %q\n", breaks[i-1], text[breaks[i-1]:breaks[i]])
+	}
+	return nil
+}
+
+// runSegmentTests 运行 GraphemeBreakTest.txt、WordBreakTest.txt 和 SentenceBreakTest.txt
+func runSegmentTests(ctx context.Context, props *segment.Properties, version, dir string) error {
+	failed := false
+	for _, f := range segmentTestFiles {
+		var content []byte
+		var err error
+		if dir != "" {
+			content, err = os.ReadFile(filepath.Join(dir, f.name))
+		} else {
+			content, _, err = fetchUcdTextWithCache(ctx, version, "auxiliary/"+f.name)
+		}
+		if err != nil {
+			return fmt.Errorf("error loading %s: %w", f.name, err)
+		}
+
+		cases, err := segment.ParseTests(bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", f.name, err)
+		}
+
+		failures := props.RunTests(f.unit, cases)
+		if len(failures) == 0 {
+			fmt.Printf("✓ %s passes (%d cases)\n", f.name, len(cases))
+			continue
+		}
+
+		failed = true
+		fmt.Printf("✗ %s: %d of %d cases fail\n", f.name, len(failures), len(cases))
+		for i, failure := range failures {
+			if i >= 5 { // 只显示前5个
+				break
+			}
+			fmt.Printf("  line %d\n    want %s\n    got  %s\n", failure.Case.Line, failure.Case.Source, segment.Format(failure.Case.Text, failure.Got))
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("segmentation does not match the Unicode %s test files", version)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package segment
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"udc2mongo/model"
+)
+
+// TestCase 官方测试文件中的一行，例如 "÷ 0020 × 0308 ÷"
+//
+// See: https://www.unicode.org/reports/tr29/#Testing
+type TestCase struct {
+	Line   int
+	Text   string
+	Breaks []int  // 期望断点的字节偏移
+	Source string // 去掉注释后的原始内容
+}
+
+// TestFailure 结果与期望不同的测试行
+type TestFailure struct {
+	Case TestCase
+	Got  []int
+}
+
+// ParseTests 解析 GraphemeBreakTest.txt、WordBreakTest.txt 或 SentenceBreakTest.txt
+//
+// 包含代理字符点的行无法表示为 UTF-8 字符串，被跳过。
+func ParseTests(r io.Reader) ([]TestCase, error) {
+	var cases []TestCase
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		tc := TestCase{Line: lineNo, Source: line}
+		var sb strings.Builder
+		surrogate := false
+		for _, field := range strings.Fields(line) {
+			switch field {
+			case "÷":
+				tc.Breaks = append(tc.Breaks, sb.Len())
+			case "×":
+			default:
+				r, err := model.ParseCodePoint(field)
+				if err != nil || r > unicode.MaxRune {
+					return nil, fmt.Errorf("line %d: invalid code point %q", lineNo, field)
+				}
+				surrogate = surrogate || !utf8.ValidRune(r)
+				sb.WriteRune(r)
+			}
+		}
+		if surrogate {
+			continue
+		}
+		tc.Text = sb.String()
+		cases = append(cases, tc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// RunTests 用属性表运行测试，返回失败的测试行
+func (p *Properties) RunTests(unit Unit, cases []TestCase) []TestFailure {
+	var failures []TestFailure
+	for _, tc := range cases {
+		got := p.Breaks(unit, tc.Text)
+		if !slices.Equal(got, tc.Breaks) {
+			failures = append(failures, TestFailure{Case: tc, Got: got})
+		}
+	}
+	return failures
+}
+
+// Format 以测试文件的格式显示断点，例如 "÷ 0020 × 0308 ÷"
+func Format(s string, breaks []int) string {
+	var sb strings.Builder
+	next := 0
+	for offset, r := range s {
+		if next < len(breaks) && breaks[next] == offset {
+			sb.WriteString("÷ ")
+			next++
+		} else {
+			sb.WriteString("× ")
+		}
+		sb.WriteString(model.FormatCodePoint(r))
+		sb.WriteString(" ")
+	}
+	if next < len(breaks) && breaks[next] == len(s) {
+		sb.WriteString("÷")
+	} else {
+		sb.WriteString("×")
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package segment
+
+// graphemeBreakAt 返回按顺序判断第 i 个字符之前是否为字素簇边界的函数
+//
+// GB9c、GB11 和 GB12/13 需要的前文在调用之间以状态保存，i 必须从 1 开始递增。
+//
+// See: https://www.unicode.org/reports/tr29/#Grapheme_Cluster_Boundary_Rules
+func (t *text) graphemeBreakAt() func(i int) bool {
+	regional := 0  // 以 i-1 结尾的连续 RI 个数
+	emoji := 0     // 1: ExtPict Extend*，2: ExtPict Extend* ZWJ
+	conjunct := 0  // 1: InCB=Consonant [Extend Linker]*，2: 其中至少有一个 Linker
+	eBase := false // (E_Base | E_Base_GAZ) Extend*
+	return func(i int) bool {
+		prev, cur := t.props[i-1], t.props[i]
+
+		if prev.gcb == gcbRegionalIndicator {
+			regional++
+		} else {
+			regional = 0
+		}
+
+		switch {
+		case prev.extPict:
+			emoji = 1
+		case emoji == 1 && prev.gcb == gcbExtend:
+		case emoji == 1 && prev.gcb == gcbZWJ:
+			emoji = 2
+		default:
+			emoji = 0
+		}
+
+		switch {
+		case prev.incb == incbConsonant:
+			conjunct = 1
+		case conjunct > 0 && prev.incb == incbLinker:
+			conjunct = 2
+		case conjunct > 0 && prev.incb == incbExtend:
+		default:
+			conjunct = 0
+		}
+
+		switch {
+		case prev.gcb == gcbEBase || prev.gcb == gcbEBaseGAZ:
+			eBase = true
+		case eBase && prev.gcb == gcbExtend:
+		default:
+			eBase = false
+		}
+
+		switch {
+		case prev.gcb == gcbCR && cur.gcb == gcbLF: // GB3
+			return false
+		case isControl(prev.gcb) || isControl(cur.gcb): // GB4, GB5
+			return true
+		case prev.gcb == gcbL && (cur.gcb == gcbL || cur.gcb == gcbV || cur.gcb == gcbLV || cur.gcb == gcbLVT): // GB6
+			return false
+		case (prev.gcb == gcbLV || prev.gcb == gcbV) && (cur.gcb == gcbV || cur.gcb == gcbT): // GB7
+			return false
+		case (prev.gcb == gcbLVT || prev.gcb == gcbT) && cur.gcb == gcbT: // GB8
+			return false
+		case cur.gcb == gcbExtend || cur.gcb == gcbZWJ: // GB9
+			return false
+		case cur.gcb == gcbSpacingMark: // GB9a
+			return false
+		case prev.gcb == gcbPrepend: // GB9b
+			return false
+		case conjunct == 2 && cur.incb == incbConsonant: // GB9c
+			return false
+		case eBase && cur.gcb == gcbEModifier: // Unicode 9.0、10.0 的 GB10
+			return false
+		case emoji == 2 && cur.extPict: // GB11
+			return false
+		case prev.gcb == gcbZWJ && (cur.gcb == gcbGlueAfterZwj || cur.gcb == gcbEBaseGAZ): // Unicode 9.0、10.0 的 GB11
+			return false
+		case regional%2 == 1 && cur.gcb == gcbRegionalIndicator: // GB12, GB13
+			return false
+		}
+		return true // GB999
+	}
+}
+
+// isControl GB4、GB5 中的 Control | CR | LF
+func isControl(gcb uint8) bool {
+	return gcb == gcbControl || gcb == gcbCR || gcb == gcbLF
+}
@@ -0,0 +1,327 @@
+package segment
+
+import (
+	"fmt"
+	"sort"
+
+	"udc2mongo/model"
+)
+
+// Grapheme_Cluster_Break 的取值，XML 中使用短名
+//
+// See: https://www.unicode.org/reports/tr29/#Grapheme_Cluster_Break_Property_Values
+const (
+	gcbOther uint8 = iota
+	gcbCR
+	gcbLF
+	gcbControl
+	gcbExtend
+	gcbZWJ
+	gcbRegionalIndicator
+	gcbPrepend
+	gcbSpacingMark
+	gcbL
+	gcbV
+	gcbT
+	gcbLV
+	gcbLVT
+	gcbEBase // E_Base、E_Modifier、Glue_After_Zwj 和 E_Base_GAZ 只出现在 Unicode 9.0、10.0
+	gcbEModifier
+	gcbGlueAfterZwj
+	gcbEBaseGAZ
+)
+
+var gcbValues = map[string]uint8{
+	"XX":                 gcbOther,
+	"Other":              gcbOther,
+	"CR":                 gcbCR,
+	"LF":                 gcbLF,
+	"CN":                 gcbControl,
+	"Control":            gcbControl,
+	"EX":                 gcbExtend,
+	"Extend":             gcbExtend,
+	"ZWJ":                gcbZWJ,
+	"RI":                 gcbRegionalIndicator,
+	"Regional_Indicator": gcbRegionalIndicator,
+	"PP":                 gcbPrepend,
+	"Prepend":            gcbPrepend,
+	"SM":                 gcbSpacingMark,
+	"SpacingMark":        gcbSpacingMark,
+	"L":                  gcbL,
+	"V":                  gcbV,
+	"T":                  gcbT,
+	"LV":                 gcbLV,
+	"LVT":                gcbLVT,
+	"EB":                 gcbEBase,
+	"E_Base":             gcbEBase,
+	"EM":                 gcbEModifier,
+	"E_Modifier":         gcbEModifier,
+	"GAZ":                gcbGlueAfterZwj,
+	"Glue_After_Zwj":     gcbGlueAfterZwj,
+	"EBG":                gcbEBaseGAZ,
+	"E_Base_GAZ":         gcbEBaseGAZ,
+}
+
+// Word_Break 的取值
+//
+// See: https://www.unicode.org/reports/tr29/#Word_Boundary_Rules
+const (
+	wbOther uint8 = iota
+	wbCR
+	wbLF
+	wbNewline
+	wbExtend
+	wbZWJ
+	wbRegionalIndicator
+	wbFormat
+	wbKatakana
+	wbHebrewLetter
+	wbALetter
+	wbSingleQuote
+	wbDoubleQuote
+	wbMidNumLet
+	wbMidLetter
+	wbMidNum
+	wbNumeric
+	wbExtendNumLet
+	wbWSegSpace
+	wbEBase // 同 gcbEBase，只出现在 Unicode 9.0、10.0
+	wbEModifier
+	wbGlueAfterZwj
+	wbEBaseGAZ
+)
+
+var wbValues = map[string]uint8{
+	"XX":                 wbOther,
+	"Other":              wbOther,
+	"CR":                 wbCR,
+	"LF":                 wbLF,
+	"NL":                 wbNewline,
+	"Newline":            wbNewline,
+	"Extend":             wbExtend,
+	"ZWJ":                wbZWJ,
+	"RI":                 wbRegionalIndicator,
+	"Regional_Indicator": wbRegionalIndicator,
+	"FO":                 wbFormat,
+	"Format":             wbFormat,
+	"KA":                 wbKatakana,
+	"Katakana":           wbKatakana,
+	"HL":                 wbHebrewLetter,
+	"Hebrew_Letter":      wbHebrewLetter,
+	"LE":                 wbALetter,
+	"ALetter":            wbALetter,
+	"SQ":                 wbSingleQuote,
+	"Single_Quote":       wbSingleQuote,
+	"DQ":                 wbDoubleQuote,
+	"Double_Quote":       wbDoubleQuote,
+	"MB":                 wbMidNumLet,
+	"MidNumLet":          wbMidNumLet,
+	"ML":                 wbMidLetter,
+	"MidLetter":          wbMidLetter,
+	"MN":                 wbMidNum,
+	"MidNum":             wbMidNum,
+	"NU":                 wbNumeric,
+	"Numeric":            wbNumeric,
+	"EX":                 wbExtendNumLet,
+	"ExtendNumLet":       wbExtendNumLet,
+	"WSegSpace":          wbWSegSpace,
+	"EB":                 wbEBase,
+	"E_Base":             wbEBase,
+	"EM":                 wbEModifier,
+	"E_Modifier":         wbEModifier,
+	"GAZ":                wbGlueAfterZwj,
+	"Glue_After_Zwj":     wbGlueAfterZwj,
+	"EBG":                wbEBaseGAZ,
+	"E_Base_GAZ":         wbEBaseGAZ,
+}
+
+// Sentence_Break 的取值
+//
+// See: https://www.unicode.org/reports/tr29/#Sentence_Boundary_Rules
+const (
+	sbOther uint8 = iota
+	sbCR
+	sbLF
+	sbSep
+	sbExtend
+	sbFormat
+	sbSp
+	sbLower
+	sbUpper
+	sbOLetter
+	sbNumeric
+	sbATerm
+	sbSContinue
+	sbSTerm
+	sbClose
+)
+
+var sbValues = map[string]uint8{
+	"XX":        sbOther,
+	"Other":     sbOther,
+	"CR":        sbCR,
+	"LF":        sbLF,
+	"SE":        sbSep,
+	"Sep":       sbSep,
+	"EX":        sbExtend,
+	"Extend":    sbExtend,
+	"FO":        sbFormat,
+	"Format":    sbFormat,
+	"SP":        sbSp,
+	"Sp":        sbSp,
+	"LO":        sbLower,
+	"Lower":     sbLower,
+	"UP":        sbUpper,
+	"Upper":     sbUpper,
+	"LE":        sbOLetter,
+	"OLetter":   sbOLetter,
+	"NU":        sbNumeric,
+	"Numeric":   sbNumeric,
+	"AT":        sbATerm,
+	"ATerm":     sbATerm,
+	"SC":        sbSContinue,
+	"SContinue": sbSContinue,
+	"ST":        sbSTerm,
+	"STerm":     sbSTerm,
+	"CL":        sbClose,
+	"Close":     sbClose,
+}
+
+// Indic_Conjunct_Break 的取值，Unicode 15.1 起才有
+const (
+	incbNone uint8 = iota
+	incbConsonant
+	incbExtend
+	incbLinker
+)
+
+var incbValues = map[string]uint8{
+	"None":      incbNone,
+	"Consonant": incbConsonant,
+	"Extend":    incbExtend,
+	"Linker":    incbLinker,
+}
+
+// props 单个字符点的断行属性
+type props struct {
+	gcb     uint8
+	wb      uint8
+	sb      uint8
+	incb    uint8
+	extPict bool
+}
+
+// span 属性相同的连续字符点
+type span struct {
+	first, last rune
+	props
+}
+
+// Properties 分段使用的属性表，按字符点排序，相邻且属性相同的条目已合并
+//
+// 表中没有的字符点取默认值 Other/None。
+type Properties struct {
+	spans []span
+}
+
+// NewProperties 从解析后的字符点建立属性表
+//
+// 属性值可以是短名或全名，未知的取值返回错误。
+func NewProperties(codePoints []model.CodePoint) (*Properties, error) {
+	spans := make([]span, 0, len(codePoints))
+	for i := range codePoints {
+		cp := &codePoints[i]
+		first, last, err := entrySpan(cp)
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := entryProps(cp)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", model.FormatCodePoint(first), err)
+		}
+		spans = append(spans, span{first: first, last: last, props: p})
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].first < spans[j].first })
+
+	merged := spans[:0]
+	for _, s := range spans {
+		if n := len(merged); n > 0 {
+			prev := &merged[n-1]
+			if s.first <= prev.last {
+				return nil, fmt.Errorf("%s: overlaps %s..%s", model.FormatCodePoint(s.first),
+					model.FormatCodePoint(prev.first), model.FormatCodePoint(prev.last))
+			}
+			if s.first == prev.last+1 && s.props == prev.props {
+				prev.last = s.last
+				continue
+			}
+		}
+		merged = append(merged, s)
+	}
+	return &Properties{spans: merged}, nil
+}
+
+// Len 合并后的条目数
+func (p *Properties) Len() int {
+	return len(p.spans)
+}
+
+// lookup 查找字符点的属性
+func (p *Properties) lookup(r rune) props {
+	i := sort.Search(len(p.spans), func(i int) bool { return p.spans[i].last >= r })
+	if i < len(p.spans) && p.spans[i].first <= r {
+		return p.spans[i].props
+	}
+	return props{}
+}
+
+// entrySpan 条目的范围，单个字符点时 first 等于 last
+func entrySpan(cp *model.CodePoint) (rune, rune, error) {
+	if cp.CP != "" {
+		r, err := model.ParseCodePoint(cp.CP)
+		return r, r, err
+	}
+	first, err := model.ParseCodePoint(cp.FirstCP)
+	if err != nil {
+		return 0, 0, err
+	}
+	last, err := model.ParseCodePoint(cp.LastCP)
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
+
+// entryProps 读取条目的 GCB、WB、SB、InCB 和 ExtPict
+func entryProps(cp *model.CodePoint) (props, error) {
+	var p props
+	var err error
+	if p.gcb, err = propertyValue(gcbValues, "GCB", cp.GraphemeClusterBreak); err != nil {
+		return p, err
+	}
+	if p.wb, err = propertyValue(wbValues, "WB", cp.WordBreak); err != nil {
+		return p, err
+	}
+	if p.sb, err = propertyValue(sbValues, "SB", cp.SentenceBreak); err != nil {
+		return p, err
+	}
+	if p.incb, err = propertyValue(incbValues, "InCB", cp.IndicConjunctBreak); err != nil {
+		return p, err
+	}
+	p.extPict = bool(cp.ExtendedPictographic)
+	return p, nil
+}
+
+// propertyValue 查找属性值，空值取默认值
+func propertyValue(values map[string]uint8, property, value string) (uint8, error) {
+	if value == "" {
+		return 0, nil
+	}
+	v, ok := values[value]
+	if !ok {
+		return 0, fmt.Errorf("unknown %s value %q", property, value)
+	}
+	return v, nil
+}
@@ -0,0 +1,94 @@
+// Package segment 按 UAX #29 把文本分成字素簇、单词和句子
+//
+// 断点规则使用从导入的 UCD 建立的属性表，结果与导入的 Unicode 版本一致。
+//
+// See: https://www.unicode.org/reports/tr29/
+package segment
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Unit 分段单位
+type Unit string
+
+const (
+	Grapheme Unit = "grapheme"
+	Word     Unit = "word"
+	Sentence Unit = "sentence"
+)
+
+// ParseUnit 解析分段单位
+func ParseUnit(s string) (Unit, error) {
+	switch u := Unit(s); u {
+	case Grapheme, Word, Sentence:
+		return u, nil
+	}
+	return "", fmt.Errorf("unknown unit %q, expected grapheme, word or sentence", s)
+}
+
+// Breaks 返回断点的字节偏移，包括 0 和 len(s)；空字符串返回 nil
+//
+// 不合法的 UTF-8 字节按 U+FFFD 处理。
+func (p *Properties) Breaks(unit Unit, s string) []int {
+	if s == "" {
+		return nil
+	}
+
+	t := p.decode(s)
+	var breakAt func(i int) bool
+	switch unit {
+	case Grapheme:
+		breakAt = t.graphemeBreakAt()
+	case Word:
+		breakAt = t.wordBreakAt
+	case Sentence:
+		breakAt = t.sentenceBreakAt
+	default:
+		panic("segment: unknown unit " + string(unit))
+	}
+
+	breaks := []int{0}
+	for i := 1; i < len(t.props); i++ {
+		if breakAt(i) {
+			breaks = append(breaks, t.offsets[i])
+		}
+	}
+	return append(breaks, len(s))
+}
+
+// Split 按断点切分文本
+func (p *Properties) Split(unit Unit, s string) []string {
+	breaks := p.Breaks(unit, s)
+	var segments []string
+	for i := 1; i < len(breaks); i++ {
+		segments = append(segments, s[breaks[i-1]:breaks[i]])
+	}
+	return segments
+}
+
+// Graphemes 切分为扩展字素簇
+func (p *Properties) Graphemes(s string) []string { return p.Split(Grapheme, s) }
+
+// Words 按单词边界切分，空白和标点也是单独的段
+func (p *Properties) Words(s string) []string { return p.Split(Word, s) }
+
+// Sentences 切分为句子
+func (p *Properties) Sentences(s string) []string { return p.Split(Sentence, s) }
+
+// text 已解码的文本，每个字符的字节偏移和属性
+type text struct {
+	offsets []int
+	props   []props
+}
+
+func (p *Properties) decode(s string) *text {
+	n := utf8.RuneCountInString(s)
+	t := &text{offsets: make([]int, 0, n), props: make([]props, 0, n)}
+	for offset, r := range s {
+		t.offsets = append(t.offsets, offset)
+		t.props = append(t.props, p.lookup(r))
+	}
+	return t
+}
@@ -0,0 +1,116 @@
+package segment
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"udc2mongo/model"
+)
+
+// testdata 中是 Unicode 15.0.0 的断点测试：三个 *BreakTest.txt 是官方文件的测试行，
+// 取自 github.com/rivo/uniseg v0.4.7 的测试表，没有保留官方文件每行的注释；
+// ucd.xml 只有测试中用到的字符点，GCB、WB、SB 和 ExtPict 取自 uniseg 由 15.0.0 UCD 生成的属性表。
+//
+// 15.0.0 还没有 InCB，GB9c 的测试行要到 15.1.0 才出现。
+
+func loadProperties(t *testing.T) *Properties {
+	t.Helper()
+	data, err := os.ReadFile("testdata/ucd.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ucd, err := model.ParseUCDXML(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codePoints := model.ExtractAllCodePoints(ucd)
+	for i := range codePoints {
+		model.NormalizeCodePoint(&codePoints[i])
+	}
+	props, err := NewProperties(codePoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return props
+}
+
+func TestConformance(t *testing.T) {
+	props := loadProperties(t)
+	tests := []struct {
+		unit Unit
+		file string
+		want int // 文件中的测试行数
+	}{
+		{Grapheme, "GraphemeBreakTest.txt", 602},
+		{Word, "WordBreakTest.txt", 1823},
+		{Sentence, "SentenceBreakTest.txt", 502},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.unit), func(t *testing.T) {
+			f, err := os.Open("testdata/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			cases, err := ParseTests(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cases) != tt.want {
+				t.Fatalf("parsed %d cases, want %d", len(cases), tt.want)
+			}
+			for _, failure := range props.RunTests(tt.unit, cases) {
+				t.Errorf("line %d: got %s, want %s", failure.Case.Line,
+					Format(failure.Case.Text, failure.Got), failure.Case.Source)
+			}
+		})
+	}
+}
+
+// TestSplit 的文本只使用 testdata/ucd.xml 中的字符点
+func TestSplit(t *testing.T) {
+	props := loadProperties(t)
+	tests := []struct {
+		unit Unit
+		text string
+		want []string
+	}{
+		{Grapheme, "", nil},
+		{Grapheme, "a\u0308b", []string{"a\u0308", "b"}},
+		{Grapheme, "\r\n\n", []string{"\r\n", "\n"}},
+		{Grapheme, "\U0001F1E6\U0001F1E7\U0001F1E6", []string{"\U0001F1E6\U0001F1E7", "\U0001F1E6"}},
+		{Word, "it's lost", []string{"it's", " ", "lost"}},
+		{Word, "3.14, so", []string{"3.14", ",", " ", "so"}},
+		{Sentence, "Hi. St. a. Do.", []string{"Hi. ", "St. a. ", "Do."}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.unit)+"/"+tt.text, func(t *testing.T) {
+			got := props.Split(tt.unit, tt.text)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Split(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Unit
+		wantErr bool
+	}{
+		{"grapheme", Grapheme, false},
+		{"word", Word, false},
+		{"sentence", Sentence, false},
+		{"line", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseUnit(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseUnit(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
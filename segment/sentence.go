@@ -0,0 +1,110 @@
+package segment
+
+// sentenceBreakAt 第 i 个字符之前是否为句子边界
+//
+// See: https://www.unicode.org/reports/tr29/#Sentence_Boundary_Rules
+func (t *text) sentenceBreakAt(i int) bool {
+	prev, cur := t.props[i-1], t.props[i]
+
+	switch {
+	case prev.sb == sbCR && cur.sb == sbLF: // SB3
+		return false
+	case isParaSep(prev.sb): // SB4
+		return true
+	case cur.sb == sbExtend || cur.sb == sbFormat: // SB5
+		return false
+	}
+
+	// SB5 之后的规则跳过 Extend 和 Format
+	p := t.sentenceBefore(i - 1)
+	before, after := t.sentenceClass(p), cur.sb
+
+	switch {
+	case before == sbATerm && after == sbNumeric: // SB6
+		return false
+	case before == sbATerm && after == sbUpper: // SB7
+		if bb := t.sentenceClass(t.sentenceBefore(p - 1)); bb == sbUpper || bb == sbLower {
+			return false
+		}
+	}
+
+	// SATerm Close*
+	k := p
+	for t.sentenceClass(k) == sbClose {
+		k = t.sentenceBefore(k - 1)
+	}
+	if isSATerm(t.sentenceClass(k)) && (after == sbClose || after == sbSp || isParaSep(after)) { // SB9
+		return false
+	}
+
+	// SATerm Close* Sp*
+	k = p
+	for t.sentenceClass(k) == sbSp {
+		k = t.sentenceBefore(k - 1)
+	}
+	for t.sentenceClass(k) == sbClose {
+		k = t.sentenceBefore(k - 1)
+	}
+	term := t.sentenceClass(k)
+	if !isSATerm(term) {
+		return false // SB998
+	}
+
+	switch {
+	case term == sbATerm && t.lowerFollows(i): // SB8
+		return false
+	case after == sbSContinue || isSATerm(after): // SB8a
+		return false
+	case after == sbSp || isParaSep(after): // SB10
+		return false
+	}
+	return true // SB11
+}
+
+// lowerFollows 从 i 开始，在 OLetter、Upper、Lower、ParaSep 和 SATerm 之前的第一个字符是否为 Lower
+func (t *text) lowerFollows(i int) bool {
+	for ; i < len(t.props); i++ {
+		switch sb := t.props[i].sb; {
+		case sb == sbLower:
+			return true
+		case sb == sbOLetter || sb == sbUpper || isParaSep(sb) || isSATerm(sb):
+			return false
+		}
+	}
+	return false
+}
+
+// sentenceBefore 按 SB5 以 j 结尾的字符在规则中的位置，j 超出范围时返回 -1
+func (t *text) sentenceBefore(j int) int {
+	if j < 0 {
+		return -1
+	}
+	k := j
+	for k >= 0 && isSentenceIgnorable(t.props[k].sb) {
+		k--
+	}
+	if k < j && (k < 0 || isParaSep(t.props[k].sb)) {
+		return k + 1
+	}
+	return k
+}
+
+// sentenceClass 第 k 个字符的 SB，超出范围时为 Other
+func (t *text) sentenceClass(k int) uint8 {
+	if k < 0 || k >= len(t.props) {
+		return sbOther
+	}
+	return t.props[k].sb
+}
+
+func isParaSep(sb uint8) bool {
+	return sb == sbSep || sb == sbCR || sb == sbLF
+}
+
+func isSentenceIgnorable(sb uint8) bool {
+	return sb == sbExtend || sb == sbFormat
+}
+
+func isSATerm(sb uint8) bool {
+	return sb == sbSTerm || sb == sbATerm
+}
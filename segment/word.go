@@ -0,0 +1,124 @@
+package segment
+
+// wordBreakAt 第 i 个字符之前是否为单词边界
+//
+// See: https://www.unicode.org/reports/tr29/#Word_Boundary_Rules
+func (t *text) wordBreakAt(i int) bool {
+	prev, cur := t.props[i-1], t.props[i]
+
+	switch {
+	case prev.wb == wbCR && cur.wb == wbLF: // WB3
+		return false
+	case isNewline(prev.wb) || isNewline(cur.wb): // WB3a, WB3b
+		return true
+	case prev.wb == wbZWJ && cur.extPict: // WB3c
+		return false
+	case prev.wb == wbZWJ && (cur.wb == wbGlueAfterZwj || cur.wb == wbEBaseGAZ): // Unicode 9.0、10.0 的 WB3c
+		return false
+	case prev.wb == wbWSegSpace && cur.wb == wbWSegSpace: // WB3d
+		return false
+	case isWordIgnorable(cur.wb): // WB4
+		return false
+	}
+
+	// WB4 之后的规则跳过 Extend、Format 和 ZWJ
+	p := t.wordBefore(i - 1)
+	before, after := t.wordClass(p), cur.wb
+	beforeBefore := t.wordClass(t.wordBefore(p - 1))
+	afterAfter := t.wordClass(t.wordAfter(i + 1))
+
+	switch {
+	case isAHLetter(before) && isAHLetter(after): // WB5
+		return false
+	case isAHLetter(before) && isMidLetter(after) && isAHLetter(afterAfter): // WB6
+		return false
+	case isAHLetter(beforeBefore) && isMidLetter(before) && isAHLetter(after): // WB7
+		return false
+	case before == wbHebrewLetter && after == wbSingleQuote: // WB7a
+		return false
+	case before == wbHebrewLetter && after == wbDoubleQuote && afterAfter == wbHebrewLetter: // WB7b
+		return false
+	case beforeBefore == wbHebrewLetter && before == wbDoubleQuote && after == wbHebrewLetter: // WB7c
+		return false
+	case before == wbNumeric && after == wbNumeric: // WB8
+		return false
+	case isAHLetter(before) && after == wbNumeric: // WB9
+		return false
+	case before == wbNumeric && isAHLetter(after): // WB10
+		return false
+	case beforeBefore == wbNumeric && isMidNum(before) && after == wbNumeric: // WB11
+		return false
+	case before == wbNumeric && isMidNum(after) && afterAfter == wbNumeric: // WB12
+		return false
+	case before == wbKatakana && after == wbKatakana: // WB13
+		return false
+	case (isAHLetter(before) || before == wbNumeric || before == wbKatakana || before == wbExtendNumLet) && after == wbExtendNumLet: // WB13a
+		return false
+	case before == wbExtendNumLet && (isAHLetter(after) || after == wbNumeric || after == wbKatakana): // WB13b
+		return false
+	case (before == wbEBase || before == wbEBaseGAZ) && after == wbEModifier: // Unicode 9.0、10.0 的 WB14
+		return false
+	case before == wbRegionalIndicator && after == wbRegionalIndicator: // WB15, WB16
+		regional := 0
+		for k := p; k >= 0 && t.wordClass(k) == wbRegionalIndicator; k = t.wordBefore(k - 1) {
+			regional++
+		}
+		return regional%2 == 0
+	}
+	return true // WB999
+}
+
+// wordBefore 按 WB4 以 j 结尾的字符在规则中的位置，j 超出范围时返回 -1
+//
+// X (Extend | Format | ZWJ)* 视为 X；开头或换行之后的 Extend 等不附着于前面的字符，视为其本身。
+func (t *text) wordBefore(j int) int {
+	if j < 0 {
+		return -1
+	}
+	k := j
+	for k >= 0 && isWordIgnorable(t.props[k].wb) {
+		k--
+	}
+	if k < j && (k < 0 || isNewline(t.props[k].wb)) {
+		return k + 1
+	}
+	return k
+}
+
+// wordAfter 从 j 开始跳过 Extend、Format 和 ZWJ
+func (t *text) wordAfter(j int) int {
+	for j < len(t.props) && isWordIgnorable(t.props[j].wb) {
+		j++
+	}
+	return j
+}
+
+// wordClass 第 k 个字符的 WB，超出范围时为 Other
+func (t *text) wordClass(k int) uint8 {
+	if k < 0 || k >= len(t.props) {
+		return wbOther
+	}
+	return t.props[k].wb
+}
+
+func isNewline(wb uint8) bool {
+	return wb == wbNewline || wb == wbCR || wb == wbLF
+}
+
+func isWordIgnorable(wb uint8) bool {
+	return wb == wbExtend || wb == wbFormat || wb == wbZWJ
+}
+
+func isAHLetter(wb uint8) bool {
+	return wb == wbALetter || wb == wbHebrewLetter
+}
+
+// isMidLetter MidLetter | MidNumLetQ
+func isMidLetter(wb uint8) bool {
+	return wb == wbMidLetter || wb == wbMidNumLet || wb == wbSingleQuote
+}
+
+// isMidNum MidNum | MidNumLetQ
+func isMidNum(wb uint8) bool {
+	return wb == wbMidNum || wb == wbMidNumLet || wb == wbSingleQuote
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"udc2mongo/casing"
+	"udc2mongo/database"
+)
+
+// maxTextRunes 单次请求最多处理的字符数
+const maxTextRunes = 4096
+
+// runServe 提供 HTTP 查询接口，Ctrl-C 时等待进行中的请求结束
+func runServe(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	defaultAddr := os.Getenv("HTTP_ADDR")
+	if defaultAddr == "" {
+		defaultAddr = ":8080"
+	}
+	addr := flags.String("addr", defaultAddr, "listen address")
+	flags.Parse(args)
+
+	mongoClient, err := connectMongo(ctx, mongoDBName())
+	if err != nil {
+		return err
+	}
+	defer mongoClient.Close()
+
+	mapper, version, err := loadServeCaseMapper(ctx, mongoClient)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/lookup", lookupHandler(mongoClient))
+	mux.Handle("/case", caseHandler(mapper, version))
+	server := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("serving HTTP API", "addr", *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("HTTP server stopped: %w", err)
+	}
+	return nil
+}
+
+// lookupHandler 处理 GET /lookup?s=... 和 POST /lookup（请求体为 UTF-8 文本）
+func lookupHandler(mongoClient *database.MongoClient) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		text, ok := readText(w, r)
+		if !ok {
+			return
+		}
+
+		infos, err := mongoClient.LookupString(r.Context(), text)
+		if err != nil {
+			slog.Error("lookup failed", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "lookup failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"characters": infos})
+	})
+}
+
+// loadServeCaseMapper 从数据库建立大小写映射表，没有导入数据时 /case 不可用
+func loadServeCaseMapper(ctx context.Context, mongoClient *database.MongoClient) (*casing.Mapper, string, error) {
+	ucd, err := mongoClient.GetUCD(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if ucd == nil {
+		slog.Warn("no UCD metadata in database, /case is disabled")
+		return nil, "", nil
+	}
+
+	slog.Info("loading case mappings", "version", ucd.Version)
+	codePoints, err := mongoClient.GetAllCodePoints(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	mapper, err := loadCaseMapper(ctx, ucd.Version, codePoints, false)
+	if err != nil {
+		return nil, "", err
+	}
+	return mapper, ucd.Version, nil
+}
+
+// caseHandler 处理 GET /case?op=upper&lang=tr&s=... 和 POST /case?op=...（请求体为 UTF-8 文本）
+func caseHandler(mapper *casing.Mapper, version string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mapper == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "no imported data")
+			return
+		}
+
+		op, err := casing.ParseOperation(r.URL.Query().Get("op"))
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		language := casing.ParseLanguage(r.URL.Query().Get("lang"))
+		if language != casing.Default && !mapper.HasSpecialCasing() {
+			writeJSONError(w, http.StatusServiceUnavailable, "special casing data is not loaded")
+			return
+		}
+
+		text, ok := readText(w, r)
+		if !ok {
+			return
+		}
+		result, err := mapper.Apply(op, text, language)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{
+			"op":              string(op),
+			"lang":            string(language),
+			"unicode_version": version,
+			"result":          result,
+		})
+	})
+}
+
+// readText 读取 GET 的 s 参数或 POST 的请求体，失败时已写入错误响应
+func readText(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var text string
+	switch r.Method {
+	case http.MethodGet:
+		text = r.URL.Query().Get("s")
+	case http.MethodPost:
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTextRunes*utf8.UTFMax))
+		if err != nil {
+			writeJSONError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return "", false
+		}
+		text = string(data)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return "", false
+	}
+
+	if n := utf8.RuneCountInString(text); n > maxTextRunes {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("text has %d characters, the limit is %d", n, maxTextRunes))
+		return "", false
+	}
+	return text, true
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to write response", "error", err)
+	}
+}
+
+// writeJSONError 写入 {"error": message}
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"udc2mongo/model"
+	"udc2mongo/ucdtxt"
+)
+
+// runUcdTxt 从解析后的数据生成 UnicodeData.txt、Blocks.txt 和 Scripts.txt
+func runUcdTxt(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ucdtxt", flag.ExitOnError)
+	source := flags.String("source", "xml", "read from the UCD XML (xml) or from MONGODB_DB (mongo)")
+	dir := flags.String("dir", ".", "output directory")
+	compareDir := flags.String("compare", "", "directory with the official files to compare against")
+	flags.Parse(args)
+
+	var version string
+	var codePoints []model.CodePoint
+	var blocks []model.Block
+	var err error
+	switch *source {
+	case "xml":
+		version = ucdVersion()
+		codePoints, blocks, err = loadFromXML(ctx, version)
+	case "mongo":
+		version, codePoints, blocks, err = loadFromMongo(ctx, mongoDBName())
+	default:
+		err = fmt.Errorf("unknown source %q", *source)
+	}
+	if err != nil {
+		return fmt.Errorf("error loading data: %w", err)
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// 官方文件的日期和版权注释不在 XML 中，比较时沿用官方文件的
+	blocksHeader, err := officialHeader(*compareDir, "Blocks.txt", version)
+	if err != nil {
+		return err
+	}
+	scriptsHeader, err := officialHeader(*compareDir, "Scripts.txt", version)
+	if err != nil {
+		return err
+	}
+	scripts, err := loadScripts(ctx, *compareDir, version)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"UnicodeData.txt", func(w io.Writer) error { return ucdtxt.WriteUnicodeData(w, codePoints) }},
+		{"Blocks.txt", func(w io.Writer) error { return ucdtxt.WriteBlocks(w, blocksHeader, blocks) }},
+		{"Scripts.txt", func(w io.Writer) error { return ucdtxt.WriteScripts(w, scriptsHeader, scripts, codePoints) }},
+	}
+
+	failed := false
+	for _, f := range files {
+		path := filepath.Join(*dir, f.name)
+		slog.Info("writing file", "path", path)
+		if err := writeTextFile(path, f.write); err != nil {
+			return fmt.Errorf("error writing %s: %w", f.name, err)
+		}
+
+		if *compareDir == "" {
+			continue
+		}
+		ok, err := compareTextFile(filepath.Join(*compareDir, f.name), path)
+		if err != nil {
+			return fmt.Errorf("error comparing %s: %w", f.name, err)
+		}
+		failed = failed || !ok
+	}
+
+	if failed {
+		return fmt.Errorf("generated files differ from the official files")
+	}
+	return nil
+}
+
+// officialHeader 从比较目录中的官方文件读取文件头，没有比较目录时只包含版本
+func officialHeader(compareDir, name, version string) (ucdtxt.Header, error) {
+	if compareDir == "" {
+		return ucdtxt.Header{Version: version}, nil
+	}
+
+	path := filepath.Join(compareDir, name)
+	file, err := os.Open(path)
+	if err != nil {
+		return ucdtxt.Header{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	header, err := ucdtxt.ReadHeader(file)
+	if err != nil {
+		return ucdtxt.Header{}, fmt.Errorf("error reading the header of %s: %w", path, err)
+	}
+	header.Version = version
+	return header, nil
+}
+
+// loadScripts 读取脚本长名称和分组顺序
+//
+// 长名称来自比较目录中的 PropertyValueAliases.txt，目录中没有时获取同一版本的文件；
+// 分组顺序来自比较目录中的 Scripts.txt，没有比较目录时按首次出现的字符点排列。
+func loadScripts(ctx context.Context, compareDir, version string) (ucdtxt.Scripts, error) {
+	var content []byte
+	var err error
+	if compareDir != "" {
+		content, err = os.ReadFile(filepath.Join(compareDir, "PropertyValueAliases.txt"))
+	}
+	if compareDir == "" || errors.Is(err, fs.ErrNotExist) {
+		content, _, err = fetchUcdTextWithCache(ctx, version, "PropertyValueAliases.txt")
+	}
+	if err != nil {
+		return ucdtxt.Scripts{}, fmt.Errorf("error loading script names: %w", err)
+	}
+
+	names, err := ucdtxt.ReadScriptNames(bytes.NewReader(content))
+	if err != nil {
+		return ucdtxt.Scripts{}, fmt.Errorf("error reading PropertyValueAliases.txt: %w", err)
+	}
+	scripts := ucdtxt.Scripts{Names: names}
+	if compareDir == "" {
+		return scripts, nil
+	}
+
+	path := filepath.Join(compareDir, "Scripts.txt")
+	file, err := os.Open(path)
+	if err != nil {
+		return ucdtxt.Scripts{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scripts.Order, err = ucdtxt.ReadScriptOrder(file)
+	if err != nil {
+		return ucdtxt.Scripts{}, fmt.Errorf("error reading the script order of %s: %w", path, err)
+	}
+	return scripts, nil
+}
+
+// writeTextFile 创建文件并写入内容
+func writeTextFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// compareTextFile 比较生成的文件与官方文件并打印差异
+func compareTextFile(officialPath, generatedPath string) (bool, error) {
+	official, err := os.Open(officialPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", officialPath, err)
+	}
+	defer official.Close()
+
+	generated, err := os.Open(generatedPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", generatedPath, err)
+	}
+	defer generated.Close()
+
+	comparison, err := ucdtxt.Compare(official, generated)
+	if err != nil {
+		return false, err
+	}
+
+	if comparison.Equal() {
+		fmt.Printf("✓ %s matches (%d lines)\n", filepath.Base(officialPath), comparison.Lines)
+		return true, nil
+	}
+
+	fmt.Printf("✗ %s: %d lines differ\n", filepath.Base(officialPath), len(comparison.Differences))
+	for i, d := range comparison.Differences {
+		if i >= 5 { // 只显示前5个
+			break
+		}
+		fmt.Printf("  line %d\n    - %s\n    + %s\n", d.Line, d.Official, d.Generated)
+	}
+	return false, nil
+}
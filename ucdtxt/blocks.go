@@ -0,0 +1,57 @@
+package ucdtxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"udc2mongo/model"
+)
+
+// blocksBody Blocks.txt 版本注释之后的说明部分
+const blocksBody = `#
+# Unicode Character Database
+# For documentation, see http://www.unicode.org/reports/tr44/
+#
+# Format:
+# Start Code..End Code; Block Name
+
+# ================================================
+
+# Note:   When comparing block names, casing, whitespace, hyphens,
+#         and underbars are ignored.
+#         For example, "Latin Extended-A" and "latin extended a" are equivalent.
+#         For more information on the comparison of property values,
+#            see UAX #44: http://www.unicode.org/reports/tr44/
+#
+#  All block ranges start with a value where (cp MOD 16) = 0,
+#  and end with a value where (cp MOD 16) = 15. In other words,
+#  the last hexadecimal digit of the start of range is ...0
+#  and the last hexadecimal digit of the end of range is ...F.
+#  This constraint on block ranges guarantees that allocations
+#  are done in terms of whole columns, and that code chart display
+#  never involves splitting columns in the charts.
+#
+#  All code points not explicitly listed for Block
+#  have the value No_Block.
+
+# Property:	Block
+#
+# @missing: 0000..10FFFF; No_Block
+
+`
+
+// WriteBlocks 生成 Blocks.txt
+//
+// See: https://www.unicode.org/reports/tr44/#Blocks.txt
+func WriteBlocks(w io.Writer, header Header, blocks []model.Block) error {
+	bw := bufio.NewWriter(w)
+	header.write(bw, "Blocks", blocksBody)
+
+	for _, b := range blocks {
+		fmt.Fprintf(bw, "%s..%s; %s\n", b.FirstCP, b.LastCP, b.Name)
+	}
+
+	fmt.Fprintf(bw, "\n# EOF\n")
+	return bw.Flush()
+}
@@ -0,0 +1,64 @@
+package ucdtxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Comparison 生成文件与官方文件逐行比较的结果，注释行和空行也参与比较
+type Comparison struct {
+	Lines       int          // 官方文件的行数
+	Differences []Difference // 内容不同的行，按行号排列
+}
+
+// Difference 同一行号上内容不同的行，一方文件较短时缺少的行为空
+type Difference struct {
+	Line      int
+	Official  string
+	Generated string
+}
+
+// Equal 检查两个文件是否逐行一致
+func (c *Comparison) Equal() bool {
+	return len(c.Differences) == 0
+}
+
+// Compare 按顺序逐行比较两个文件
+func Compare(official, generated io.Reader) (*Comparison, error) {
+	want, err := readLines(official)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read official file: %w", err)
+	}
+	got, err := readLines(generated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read generated file: %w", err)
+	}
+
+	comparison := &Comparison{Lines: len(want)}
+	for i := 0; i < max(len(want), len(got)); i++ {
+		var w, g string
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if i >= len(want) || i >= len(got) || w != g {
+			comparison.Differences = append(comparison.Differences, Difference{Line: i + 1, Official: w, Generated: g})
+		}
+	}
+
+	return comparison, nil
+}
+
+// readLines 读取所有行
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,54 @@
+package ucdtxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Header 文件开头的版本和版权注释
+//
+// 官方文件的发布日期和版权措辞每个版本都不同，XML 中没有这些信息，由 ReadHeader 从官方文件读取；
+// 没有官方文件时只输出文件名行。
+type Header struct {
+	Version string
+	Lines   []string // 文件名行之后、第一个 "#" 行之前的注释行，例如 Date 和版权行
+}
+
+var headerFileName = regexp.MustCompile(`^# \w+-(\d+\.\d+\.\d+)\.txt$`)
+
+// ReadHeader 读取官方文件的文件名行和其后的版本、版权注释
+func ReadHeader(r io.Reader) (Header, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return Header{}, err
+		}
+		return Header{}, fmt.Errorf("empty file")
+	}
+	m := headerFileName.FindStringSubmatch(scanner.Text())
+	if m == nil {
+		return Header{}, fmt.Errorf("unexpected first line %q", scanner.Text())
+	}
+
+	header := Header{Version: m[1]}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "#" || !strings.HasPrefix(line, "#") {
+			break
+		}
+		header.Lines = append(header.Lines, line)
+	}
+	return header, scanner.Err()
+}
+
+// write 输出文件名行、版本和版权注释，以及文件固定的说明部分
+func (h Header) write(w io.Writer, file, body string) {
+	fmt.Fprintf(w, "# %s-%s.txt\n", file, h.Version)
+	for _, line := range h.Lines {
+		fmt.Fprintln(w, line)
+	}
+	io.WriteString(w, body)
+}
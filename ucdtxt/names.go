@@ -0,0 +1,91 @@
+package ucdtxt
+
+import (
+	"sort"
+	"strings"
+
+	"udc2mongo/model"
+)
+
+// entry 展开后的单个已分配字符点
+type entry struct {
+	cp    rune
+	props *model.CodePoint
+}
+
+// expand 展开范围条目，只保留已分配的字符点并按字符点排序
+func expand(codePoints []model.CodePoint) ([]entry, error) {
+	var entries []entry
+	for i := range codePoints {
+		cp := &codePoints[i]
+		if cp.GeneralCategory == "" || cp.GeneralCategory == "Cn" {
+			continue
+		}
+
+		if cp.CP != "" {
+			r, err := model.ParseCodePoint(cp.CP)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry{r, cp})
+			continue
+		}
+
+		first, err := model.ParseCodePoint(cp.FirstCP)
+		if err != nil {
+			return nil, err
+		}
+		last, err := model.ParseCodePoint(cp.LastCP)
+		if err != nil {
+			return nil, err
+		}
+		for r := first; r <= last; r++ {
+			entries = append(entries, entry{r, cp})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].cp < entries[j].cp })
+	return entries, nil
+}
+
+// 韩文音节名称算法使用的字母短名，见 Unicode 标准第 3.12 节
+var (
+	jamoL = []string{"G", "GG", "N", "D", "DD", "R", "M", "B", "BB", "S", "SS", "", "J", "JJ", "C", "K", "T", "P", "H"}
+	jamoV = []string{"A", "AE", "YA", "YAE", "EO", "E", "YEO", "YE", "O", "WA", "WAE", "OE", "YO", "U", "WEO", "WE", "WI", "YU", "EU", "YI", "I"}
+	jamoT = []string{"", "G", "GG", "GS", "N", "NJ", "NH", "D", "L", "LG", "LM", "LB", "LS", "LT", "LP", "LH", "M", "B", "BS", "S", "SS", "NG", "J", "C", "K", "T", "P", "H"}
+)
+
+const (
+	hangulBase  = 0xAC00
+	hangulCount = 11172
+)
+
+// CharacterName 返回字符名称，展开 na 中的 # 并按算法生成韩文音节名称
+//
+// See: https://www.unicode.org/reports/tr42/#d1e3071
+func CharacterName(r rune, cp *model.CodePoint) string {
+	if r >= hangulBase && r < hangulBase+hangulCount && (cp.Name == "" || strings.Contains(cp.Name, "#")) {
+		s := int(r - hangulBase)
+		return "HANGUL SYLLABLE " + jamoL[s/(21*28)] + jamoV[s%(21*28)/28] + jamoT[s%28]
+	}
+
+	return strings.ReplaceAll(cp.Name, "#", model.FormatCodePoint(r))
+}
+
+// commentName 返回注释中使用的名称，无名称的字符使用 <control-XXXX> 形式的标签
+func commentName(r rune, cp *model.CodePoint) string {
+	if name := CharacterName(r, cp); name != "" {
+		return name
+	}
+
+	label := "reserved"
+	switch cp.GeneralCategory {
+	case "Cc":
+		label = "control"
+	case "Co":
+		label = "private-use"
+	case "Cs":
+		label = "surrogate"
+	}
+	return "<" + label + "-" + model.FormatCodePoint(r) + ">"
+}
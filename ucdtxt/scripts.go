@@ -0,0 +1,172 @@
+package ucdtxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"udc2mongo/model"
+)
+
+// Scripts 脚本的长名称和官方分组顺序，这两者都不在 UCD XML 中
+type Scripts struct {
+	Names map[string]string // sc 短别名对应的长名称，见 ReadScriptNames
+	Order []string          // 官方 Scripts.txt 中脚本分组的顺序（长名称），见 ReadScriptOrder
+}
+
+// ReadScriptNames 从 PropertyValueAliases.txt 读取 sc 短别名对应的长名称
+//
+// See: https://www.unicode.org/Public/UCD/latest/ucd/PropertyValueAliases.txt
+func ReadScriptNames(r io.Reader) (map[string]string, error) {
+	names := make(map[string]string)
+	err := eachDataLine(r, func(fields []string) {
+		if len(fields) >= 3 && fields[0] == "sc" {
+			names[fields[1]] = fields[2]
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no sc values found")
+	}
+	return names, nil
+}
+
+// ReadScriptOrder 从官方 Scripts.txt 读取脚本分组的顺序
+//
+// 官方文件中的分组大致按脚本加入 UCD 的先后排列，无法从数据推出。
+func ReadScriptOrder(r io.Reader) ([]string, error) {
+	var order []string
+	seen := make(map[string]bool)
+	err := eachDataLine(r, func(fields []string) {
+		if len(fields) >= 2 && !seen[fields[1]] {
+			seen[fields[1]] = true
+			order = append(order, fields[1])
+		}
+	})
+	return order, err
+}
+
+// eachDataLine 对每个非空的数据行调用 fn，字段按 ; 切分并去掉注释和首尾空白
+func eachDataLine(r io.Reader, fn func(fields []string)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		fn(fields)
+	}
+	return scanner.Err()
+}
+
+const groupSeparator = "# ================================================\n\n"
+
+// scriptsBody Scripts.txt 版本注释之后的说明部分
+const scriptsBody = `#
+# Unicode Character Database
+# For documentation, see http://www.unicode.org/reports/tr44/
+# For more information, see:
+#   UAX #24, Unicode Script Property: https://www.unicode.org/reports/tr24/
+#     Especially the sections:
+#       https://www.unicode.org/reports/tr24/#Assignment_Script_Values
+#       https://www.unicode.org/reports/tr24/#Assignment_ScriptX_Values
+#
+
+# ================================================
+
+# Property:	Script
+
+#  All code points not explicitly listed for Script
+#  have the value Unknown (Zzzz).
+
+# @missing: 0000..10FFFF; Unknown
+
+`
+
+// WriteScripts 生成 Scripts.txt
+//
+// 脚本分组按 scripts.Order 排列，其中没有的脚本按首次出现的字符点排在最后；
+// Order 为空时全部按首次出现的字符点排列。
+//
+// See: https://www.unicode.org/reports/tr24/
+func WriteScripts(w io.Writer, header Header, scripts Scripts, codePoints []model.CodePoint) error {
+	entries, err := expand(codePoints)
+	if err != nil {
+		return err
+	}
+
+	// 按脚本长名称分组，保持字符点顺序
+	var unlisted []string
+	groups := make(map[string][]entry)
+	for _, e := range entries {
+		sc := e.props.Script
+		if sc == "" || sc == "Zzzz" {
+			continue
+		}
+		name, ok := scripts.Names[sc]
+		if !ok {
+			return fmt.Errorf("no long name for script %s", sc)
+		}
+		if _, ok := groups[name]; !ok && !slices.Contains(scripts.Order, name) {
+			unlisted = append(unlisted, name)
+		}
+		groups[name] = append(groups[name], e)
+	}
+
+	bw := bufio.NewWriter(w)
+	header.write(bw, "Scripts", scriptsBody)
+
+	for _, name := range append(slices.Clone(scripts.Order), unlisted...) {
+		group, ok := groups[name]
+		if !ok {
+			continue
+		}
+
+		bw.WriteString(groupSeparator)
+		for i := 0; i < len(group); i++ {
+			j := i
+			for j+1 < len(group) && group[j+1].cp == group[j].cp+1 &&
+				scriptCategory(group[j+1].props) == scriptCategory(group[i].props) {
+				j++
+			}
+			bw.WriteString(scriptLine(name, group[i], group[j], j-i+1))
+			i = j
+		}
+
+		fmt.Fprintf(bw, "\n# Total code points: %d\n\n", len(group))
+	}
+
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// scriptLine 生成一行，例如 0041..005A    ; Latin # L&  [26] LATIN CAPITAL LETTER A..LATIN CAPITAL LETTER Z
+func scriptLine(script string, first, last entry, count int) string {
+	names := commentName(first.cp, first.props)
+	counter := "     "
+	if count > 1 {
+		counter = fmt.Sprintf("%5s", fmt.Sprintf("[%d]", count))
+		names += ".." + commentName(last.cp, last.props)
+	}
+
+	return fmt.Sprintf("%-14s; %s # %s %s %s\n",
+		FormatRange(first.cp, last.cp), script, scriptCategory(first.props), counter, names)
+}
+
+// scriptCategory Scripts.txt 将 Lu、Ll、Lt 合并为 L&
+func scriptCategory(cp *model.CodePoint) string {
+	switch cp.GeneralCategory {
+	case "Lu", "Ll", "Lt":
+		return "L&"
+	default:
+		return cp.GeneralCategory
+	}
+}
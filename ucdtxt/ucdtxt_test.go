@@ -0,0 +1,272 @@
+package ucdtxt
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"udc2mongo/model"
+)
+
+// testdata 中是 Unicode 14.0.0 的文件：Blocks.txt 是完整的官方文件；
+// UnicodeData.txt 是官方文件中部分字符的行（包括几个 First/Last 范围）；
+// Scripts.txt 是按官方格式和分组顺序、只包含这些字符的 Scripts.txt；
+// PropertyValueAliases.txt 只有 sc 部分，按官方格式由 14.0.0 的别名生成。
+//
+// 测试把这些文件解析成 model 中的结构，再用 Write* 生成，结果应与原文件逐字节一致。
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func fixtureHeader(t *testing.T, data []byte) Header {
+	t.Helper()
+	header, err := ReadHeader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != "14.0.0" {
+		t.Fatalf("fixture version %q, want 14.0.0", header.Version)
+	}
+	return header
+}
+
+// fixtureLines 返回非注释、非空行按 ; 切分的字段，去掉注释和首尾空白
+func fixtureLines(data []byte) [][]string {
+	var lines [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		lines = append(lines, fields)
+	}
+	return lines
+}
+
+// parseUnicodeData 把 UnicodeData.txt 解析为 XML 中对应的属性
+func parseUnicodeData(t *testing.T, data []byte) []model.CodePoint {
+	t.Helper()
+
+	blocks := make(map[string]string, len(rangeLabels))
+	for alias, label := range rangeLabels {
+		blocks[label] = alias
+	}
+	dts := make(map[string]string, len(decompositionTags))
+	for dt, tag := range decompositionTags {
+		dts["<"+tag+">"] = dt
+	}
+
+	var codePoints []model.CodePoint
+	lines := fixtureLines(data)
+	for i := 0; i < len(lines); i++ {
+		f := lines[i]
+		var cp model.CodePoint
+		cp.GeneralCategory = f[2]
+		cp.CombiningClass, _ = strconv.Atoi(f[3])
+		cp.BidiClass = f[4]
+		cp.BidiMirrored = f[9] == "Y"
+
+		if label, ok := strings.CutSuffix(f[1], ", First>"); ok {
+			label = strings.TrimPrefix(label, "<")
+			cp.FirstCP, cp.LastCP = f[0], lines[i+1][0]
+			cp.Block = blocks[label]
+			if cp.Block == "" {
+				t.Fatalf("unknown range %q", label)
+			}
+			if strings.HasPrefix(label, "CJK Ideograph") {
+				cp.Name = "CJK UNIFIED IDEOGRAPH-#"
+			}
+			codePoints = append(codePoints, cp)
+			i++
+			continue
+		}
+
+		cp.CP = f[0]
+		if f[1] != "<control>" {
+			cp.Name = f[1]
+		}
+		cp.DecompositionType, cp.DecompositionMapping = "none", f[5]
+		if tag, mapping, ok := strings.Cut(f[5], " "); ok && strings.HasPrefix(tag, "<") {
+			cp.DecompositionType, cp.DecompositionMapping = dts[tag], mapping
+		} else if f[5] != "" {
+			cp.DecompositionType = "can"
+		}
+		cp.NumericType, cp.NumericValue = "None", "NaN"
+		switch {
+		case f[6] != "":
+			cp.NumericType = "De"
+		case f[7] != "":
+			cp.NumericType = "Di"
+		case f[8] != "":
+			cp.NumericType = "Nu"
+		}
+		if f[8] != "" {
+			cp.NumericValue = f[8]
+		}
+		cp.Name1 = f[10]
+		cp.ISOComment = f[11]
+		cp.SimpleUppercase, cp.SimpleLowercase, cp.SimpleTitlecase = f[12], f[13], f[14]
+		codePoints = append(codePoints, cp)
+	}
+	return codePoints
+}
+
+func TestWriteUnicodeData(t *testing.T) {
+	want := readFixture(t, "UnicodeData.txt")
+
+	var buf bytes.Buffer
+	if err := WriteUnicodeData(&buf, parseUnicodeData(t, want)); err != nil {
+		t.Fatal(err)
+	}
+	assertSameFile(t, "UnicodeData.txt", want, buf.Bytes())
+}
+
+func TestWriteBlocks(t *testing.T) {
+	want := readFixture(t, "Blocks.txt")
+
+	var blocks []model.Block
+	for _, f := range fixtureLines(want) {
+		first, last, _ := strings.Cut(f[0], "..")
+		blocks = append(blocks, model.Block{FirstCP: first, LastCP: last, Name: f[1]})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBlocks(&buf, fixtureHeader(t, want), blocks); err != nil {
+		t.Fatal(err)
+	}
+	assertSameFile(t, "Blocks.txt", want, buf.Bytes())
+}
+
+// fixtureScripts 读取脚本长名称，并按 Scripts.txt 为 UnicodeData.txt 中的字符填写脚本
+func fixtureScripts(t *testing.T) ([]model.CodePoint, map[string]string) {
+	t.Helper()
+	names, err := ReadScriptNames(bytes.NewReader(readFixture(t, "PropertyValueAliases.txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliases := make(map[string]string, len(names))
+	for alias, name := range names {
+		aliases[name] = alias
+	}
+
+	scripts := make(map[rune]string)
+	for _, f := range fixtureLines(readFixture(t, "Scripts.txt")) {
+		first, last, _ := strings.Cut(f[0], "..")
+		if last == "" {
+			last = first
+		}
+		r1, _ := model.ParseCodePoint(first)
+		r2, _ := model.ParseCodePoint(last)
+		for r := r1; r <= r2; r++ {
+			scripts[r] = aliases[f[1]]
+		}
+	}
+
+	// 官方文件不列出 Unknown，范围条目内的脚本相同
+	codePoints := parseUnicodeData(t, readFixture(t, "UnicodeData.txt"))
+	for i := range codePoints {
+		cp := &codePoints[i]
+		first := cp.CP
+		if first == "" {
+			first = cp.FirstCP
+		}
+		r, _ := model.ParseCodePoint(first)
+		cp.Script = "Zzzz"
+		if sc, ok := scripts[r]; ok {
+			cp.Script = sc
+		}
+	}
+	return codePoints, names
+}
+
+func TestWriteScripts(t *testing.T) {
+	want := readFixture(t, "Scripts.txt")
+	codePoints, names := fixtureScripts(t)
+
+	order, err := ReadScriptOrder(bytes.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteScripts(&buf, fixtureHeader(t, want), Scripts{Names: names, Order: order}, codePoints); err != nil {
+		t.Fatal(err)
+	}
+	assertSameFile(t, "Scripts.txt", want, buf.Bytes())
+}
+
+func TestWriteScriptsWithoutOrder(t *testing.T) {
+	codePoints, names := fixtureScripts(t)
+
+	var buf bytes.Buffer
+	if err := WriteScripts(&buf, Header{Version: "14.0.0"}, Scripts{Names: names}, codePoints); err != nil {
+		t.Fatal(err)
+	}
+	order, err := ReadScriptOrder(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有官方顺序时按首次出现的字符点排列：Common 从 0000 开始，Latin 从 0041 开始
+	if len(order) < 2 || order[0] != "Common" || order[1] != "Latin" {
+		t.Errorf("order = %v, want Common, Latin first", order)
+	}
+
+	delete(names, "Latn")
+	if err := WriteScripts(&buf, Header{Version: "14.0.0"}, Scripts{Names: names}, codePoints); err == nil {
+		t.Error("missing long name for Latn: got nil error")
+	}
+}
+
+func TestCompareOrder(t *testing.T) {
+	official := "# header\n0041; A\n0042; B\n"
+	generated := "# header\n0042; B\n0041; A\n"
+
+	comparison, err := Compare(strings.NewReader(official), strings.NewReader(generated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.Equal() || len(comparison.Differences) != 2 || comparison.Differences[0].Line != 2 {
+		t.Errorf("swapped lines: got %+v", comparison.Differences)
+	}
+
+	comparison, err = Compare(strings.NewReader(official), strings.NewReader("# other\n0041; A\n0042; B\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.Equal() {
+		t.Error("changed comment line compares equal")
+	}
+}
+
+// assertSameFile 逐字节比较，不同时报告第一处不同的行
+func assertSameFile(t *testing.T, name string, want, got []byte) {
+	t.Helper()
+	if bytes.Equal(want, got) {
+		return
+	}
+	comparison, err := Compare(bytes.NewReader(want), bytes.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.Equal() {
+		t.Fatalf("%s: generated file differs from the fixture in line endings", name)
+	}
+	d := comparison.Differences[0]
+	t.Fatalf("%s: %d lines differ, first at line %d\n want %q\n got  %q",
+		name, len(comparison.Differences), d.Line, d.Official, d.Generated)
+}
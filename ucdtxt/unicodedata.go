@@ -0,0 +1,159 @@
+package ucdtxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"udc2mongo/model"
+)
+
+// rangeLabels UnicodeData.txt 中以 First/Last 两行表示的范围，按 blk 别名索引
+var rangeLabels = map[string]string{
+	"CJK":                "CJK Ideograph",
+	"CJK_Ext_A":          "CJK Ideograph Extension A",
+	"CJK_Ext_B":          "CJK Ideograph Extension B",
+	"CJK_Ext_C":          "CJK Ideograph Extension C",
+	"CJK_Ext_D":          "CJK Ideograph Extension D",
+	"CJK_Ext_E":          "CJK Ideograph Extension E",
+	"CJK_Ext_F":          "CJK Ideograph Extension F",
+	"CJK_Ext_G":          "CJK Ideograph Extension G",
+	"CJK_Ext_H":          "CJK Ideograph Extension H",
+	"CJK_Ext_I":          "CJK Ideograph Extension I",
+	"Hangul":             "Hangul Syllable",
+	"High_Surrogates":    "Non Private Use High Surrogate",
+	"High_PU_Surrogates": "Private Use High Surrogate",
+	"Low_Surrogates":     "Low Surrogate",
+	"PUA":                "Private Use",
+	"Tangut":             "Tangut Ideograph",
+	"Tangut_Sup":         "Tangut Ideograph Supplement",
+	"Sup_PUA_A":          "Plane 15 Private Use",
+	"Sup_PUA_B":          "Plane 16 Private Use",
+}
+
+// decompositionTags dt 短别名对应的 UnicodeData.txt 标签，can 和 none 没有标签
+var decompositionTags = map[string]string{
+	"com":  "compat",
+	"enc":  "circle",
+	"fin":  "final",
+	"font": "font",
+	"fra":  "fraction",
+	"init": "initial",
+	"iso":  "isolated",
+	"med":  "medial",
+	"nar":  "narrow",
+	"nb":   "noBreak",
+	"sml":  "small",
+	"sqr":  "square",
+	"sub":  "sub",
+	"sup":  "super",
+	"vert": "vertical",
+	"wide": "wide",
+}
+
+// WriteUnicodeData 生成 UnicodeData.txt
+//
+// See: https://www.unicode.org/reports/tr44/#UnicodeData.txt
+func WriteUnicodeData(w io.Writer, codePoints []model.CodePoint) error {
+	entries, err := expand(codePoints)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+
+		label, ok := rangeLabels[e.props.Block]
+		if !ok {
+			bw.WriteString(unicodeDataLine(e.cp, e.props, CharacterName(e.cp, e.props), false))
+			continue
+		}
+
+		// 合并连续的同块字符点
+		j := i
+		for j+1 < len(entries) && entries[j+1].cp == entries[j].cp+1 && entries[j+1].props.Block == e.props.Block {
+			j++
+		}
+		bw.WriteString(unicodeDataLine(e.cp, e.props, "<"+label+", First>", true))
+		bw.WriteString(unicodeDataLine(entries[j].cp, e.props, "<"+label+", Last>", true))
+		i = j
+	}
+
+	return bw.Flush()
+}
+
+// unicodeDataLine 生成一行，范围行只保留前五个字段和 Bidi_M
+func unicodeDataLine(r rune, cp *model.CodePoint, name string, rangeLine bool) string {
+	if name == "" && cp.GeneralCategory == "Cc" {
+		name = "<control>"
+	}
+
+	fields := make([]string, 15)
+	fields[0] = model.FormatCodePoint(r)
+	fields[1] = name
+	fields[2] = cp.GeneralCategory
+	fields[3] = strconv.Itoa(cp.CombiningClass)
+	fields[4] = cp.BidiClass
+	fields[9] = "N"
+	if cp.BidiMirrored {
+		fields[9] = "Y"
+	}
+
+	if !rangeLine {
+		fields[5] = decomposition(cp)
+		fields[6], fields[7], fields[8] = numericFields(cp)
+		fields[10] = cp.Name1
+		fields[11] = cp.ISOComment
+		fields[12] = cp.SimpleUppercase
+		fields[13] = cp.SimpleLowercase
+		fields[14] = cp.SimpleTitlecase
+	}
+
+	return strings.Join(fields, ";") + "\n"
+}
+
+// decomposition 生成第 5 个字段
+func decomposition(cp *model.CodePoint) string {
+	if cp.DecompositionMapping == "" || cp.DecompositionType == "none" {
+		return ""
+	}
+	if tag, ok := decompositionTags[cp.DecompositionType]; ok {
+		return "<" + tag + "> " + cp.DecompositionMapping
+	}
+	return cp.DecompositionMapping
+}
+
+// numericFields 生成第 6 到 8 个字段
+//
+// 汉字的数值来自 Unihan（kPrimaryNumeric 等），不出现在 UnicodeData.txt 中。
+func numericFields(cp *model.CodePoint) (string, string, string) {
+	nv := cp.NumericValue
+	if nv == "NaN" {
+		nv = ""
+	}
+
+	switch cp.NumericType {
+	case "De":
+		return nv, nv, nv
+	case "Di":
+		return "", nv, nv
+	case "Nu":
+		if cp.GeneralCategory == "Lo" && cp.Ideographic {
+			return "", "", ""
+		}
+		return "", "", nv
+	default:
+		return "", "", ""
+	}
+}
+
+// FormatRange 格式化为 XXXX 或 XXXX..YYYY
+func FormatRange(first, last rune) string {
+	if first == last {
+		return model.FormatCodePoint(first)
+	}
+	return fmt.Sprintf("%s..%s", model.FormatCodePoint(first), model.FormatCodePoint(last))
+}